@@ -0,0 +1,34 @@
+package app
+
+import (
+	"crypto/rsa"
+	"errors"
+
+	"github.com/typical-go/typical-rest-server/config"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+// ErrMissingJWTKey returned when neither JWT secret nor public key is configured while authentication is not disabled
+var ErrMissingJWTKey = errors.New("set APP_JWT_SECRET or APP_JWT_PUBLIC_KEY_FILE to authenticate the request, or APP_AUTH_DISABLED=true to turn it off")
+
+// NewVerifier return verifier of bearer token according the configuration; the server refuse to start without key to verify the token
+// unless authentication is disabled
+func NewVerifier(config config.AppConfig) (*jwtauth.Verifier, error) {
+	if !config.AuthDisabled && config.JWTSecret == "" && config.JWTPublicKeyFile == "" {
+		return nil, ErrMissingJWTKey
+	}
+
+	var publicKey *rsa.PublicKey
+	if config.JWTPublicKeyFile != "" {
+		var err error
+		if publicKey, err = jwtauth.LoadPublicKey(config.JWTPublicKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	return jwtauth.New(jwtauth.Config{
+		Secret:    []byte(config.JWTSecret),
+		PublicKey: publicKey,
+		Issuer:    config.JWTIssuer,
+		Audience:  config.JWTAudience,
+	}), nil
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/config"
+)
+
+func TestNewVerifier(t *testing.T) {
+	_, err := NewVerifier(config.AppConfig{})
+	require.Equal(t, ErrMissingJWTKey, err)
+
+	verifier, err := NewVerifier(config.AppConfig{AuthDisabled: true})
+	require.NoError(t, err)
+	require.NotNil(t, verifier)
+
+	verifier, err = NewVerifier(config.AppConfig{JWTSecret: "some-secret"})
+	require.NoError(t, err)
+	require.NotNil(t, verifier)
+}
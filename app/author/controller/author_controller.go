@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/base"
+	bookmodels "github.com/typical-go/typical-rest-server/app/book/models"
+	bookservice "github.com/typical-go/typical-rest-server/app/book/service"
+	"github.com/typical-go/typical-rest-server/app/crud"
+	"github.com/typical-go/typical-rest-server/app/helper/strkit"
+)
+
+// AuthorController handle input related to Author
+type AuthorController interface {
+	base.BaseCRUDController
+	//Put new route belows
+	Books(c echo.Context) error
+}
+
+//InitAuthorController struct
+type InitAuthorController struct {
+	*crud.Controller
+	Book bookservice.BookService
+}
+
+// NewAuthorController return new instance of author controller; author name of the books is changed with the author
+func NewAuthorController(conn *sql.DB, bookService bookservice.BookService) AuthorController {
+	authorRepository := repository.NewAuthorRepository(conn)
+	hooks := crud.Hooks{
+		AfterUpdate: func(ctx context.Context, entity crud.Entity) error {
+			return authorRepository.RenameBooks(ctx, crud.ID(entity))
+		},
+		BeforeDelete: authorRepository.RemoveFromBooks,
+	}
+	return &InitAuthorController{
+		Controller: crud.NewController("author", repository.AuthorTable,
+			crud.NewService(crud.NewRepository(conn, repository.AuthorTable), hooks)),
+		Book: bookService,
+	}
+}
+
+//Books func
+func (c *InitAuthorController) Books(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return base.InvalidID(err)
+	}
+
+	author, err := c.Service.Find(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	if author == nil {
+		return c.NotFound(id)
+	}
+
+	criteria := bookmodels.BookCriteria{
+		AuthorID: id,
+		Sort:     ctx.QueryParam("sort"),
+		Page:     1,
+		Size:     bookmodels.DefaultPageSize,
+	}
+	if criteria.Page, err = uintQueryParam(ctx, "page", criteria.Page); err != nil || criteria.Page < 1 {
+		return base.NewValidationError(err)
+	}
+	if criteria.Size, err = uintQueryParam(ctx, "size", criteria.Size); err != nil || criteria.Size < 1 || criteria.Size > bookmodels.MaxPageSize {
+		return base.NewValidationError(err)
+	}
+
+	books, total, err := c.Book.ListBook(ctx.Request().Context(), criteria)
+	if errors.Is(err, bookmodels.ErrInvalidSort) {
+		return base.NewValidationError(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data: books,
+		Meta: base.Pagination{
+			Page:       criteria.Page,
+			Size:       criteria.Size,
+			Total:      total,
+			TotalPages: criteria.TotalPages(total),
+		},
+	})
+}
+
+func uintQueryParam(ctx echo.Context, name string, defaultValue uint64) (uint64, error) {
+	s := ctx.QueryParam(name)
+	if s == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return value, nil
+}
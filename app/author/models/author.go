@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+// Error of author operation
+var (
+	// ErrDuplicateName returned when other author already has the same name
+	ErrDuplicateName = errors.New("author name already exist")
+	// ErrAuthorNotFound returned when book is linked to author which is not exist
+	ErrAuthorNotFound = errors.New("author not found")
+)
+
+// Author represented database model
+type Author struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	UpdatedAt time.Time `json:"-"`
+	CreatedAt time.Time `json:"-"`
+}
+
+//ScanAuthor func
+func ScanAuthor(rows *sql.Rows) (*Author, error) {
+	var author Author
+	err := rows.Scan(author.Fields()...)
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// Fields return pointer of author fields as scan destination in the same order with the author columns
+func (a *Author) Fields() []interface{} {
+	return []interface{}{&a.ID, &a.Name, &a.UpdatedAt, &a.CreatedAt}
+}
+
+// Validate author
+func (a *Author) Validate() error {
+	return validkit.Struct(a)
+}
+
+// JoinNames return names of the authors in order; it is the author name of the book which is linked to the authors
+func JoinNames(authors []*Author) string {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthor_Validate(t *testing.T) {
+	author := Author{}
+	err := author.Validate()
+	require.EqualError(t, err, `Key: 'Author.name' Error:Field validation for 'name' failed on the 'required' tag`)
+}
+
+func TestJoinNames(t *testing.T) {
+	require.Equal(t, "", JoinNames(nil))
+	require.Equal(t, "some-name1, some-name2", JoinNames([]*Author{{Name: "some-name1"}, {Name: "some-name2"}}))
+}
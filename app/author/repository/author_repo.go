@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/typical-go/typical-rest-server/app/author/models"
+	"github.com/typical-go/typical-rest-server/app/crud"
+	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
+)
+
+// AuthorTable map author into database table for its create read update delete
+var AuthorTable = crud.Table{
+	Name:      authorTable,
+	Columns:   AuthorColumns,
+	Writable:  []string{authorNameColumn},
+	UpdatedAt: updatedAtColumn,
+	OrderBy:   []string{authorNameColumn + " ASC", idColumn + " ASC"},
+	Search:    map[string]string{"name_like": authorNameColumn},
+	Sort:      map[string]string{"id": idColumn, "name": authorNameColumn},
+	Unique:    map[string]error{authorNameConstraint: models.ErrDuplicateName},
+	New: func() crud.Entity {
+		return &models.Author{}
+	},
+}
+
+// AuthorRepository to get author data of book from database
+type AuthorRepository interface {
+	ListByBooks(ctx context.Context, bookIDs []int64) (map[int64][]*models.Author, error)
+	FindByIDs(ctx context.Context, ids []int64) ([]*models.Author, error)
+	FindOrCreate(ctx context.Context, name string) (*models.Author, error)
+	SetBookAuthors(ctx context.Context, bookID int64, authorIDs []int64) error
+	RenameBooks(ctx context.Context, authorID int64) error
+	RemoveFromBooks(ctx context.Context, authorID int64) error
+}
+
+//InitAuthorRepository struct
+type InitAuthorRepository struct {
+	conn *sql.DB
+}
+
+// NewAuthorRepository return new instance of AuthorRepository
+func NewAuthorRepository(conn *sql.DB) AuthorRepository {
+	return &InitAuthorRepository{
+		conn: conn,
+	}
+}
+
+//ListByBooks func
+func (r *InitAuthorRepository) ListByBooks(ctx context.Context, bookIDs []int64) (authors map[int64][]*models.Author, err error) {
+	authors = make(map[int64][]*models.Author)
+	if len(bookIDs) == 0 {
+		return authors, nil
+	}
+
+	columns := []string{"ba." + bookIDColumn}
+	for _, column := range AuthorColumns {
+		columns = append(columns, "a."+column)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(columns...).
+		From(bookAuthorTable + " ba").
+		Join(fmt.Sprintf("%s a ON a.%s = ba.%s", authorTable, idColumn, authorIDColumn)).
+		Where(sq.Eq{"ba." + bookIDColumn: bookIDs}).
+		OrderBy("ba."+bookIDColumn, "ba."+positionColumn, "a."+idColumn)
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return authors, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookID int64
+		var author models.Author
+		if err = rows.Scan(append([]interface{}{&bookID}, author.Fields()...)...); err != nil {
+			return
+		}
+		authors[bookID] = append(authors[bookID], &author)
+	}
+
+	return authors, rows.Err()
+}
+
+//FindByIDs func
+func (r *InitAuthorRepository) FindByIDs(ctx context.Context, ids []int64) (authors []*models.Author, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(AuthorColumns...).
+		From(authorTable).
+		Where(sq.Eq{idColumn: ids})
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]*models.Author)
+	for rows.Next() {
+		var author models.Author
+		if err = rows.Scan(author.Fields()...); err != nil {
+			return nil, err
+		}
+		found[author.ID] = &author
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// NOTE: keep order of the given ids and skip the repeated one
+	for _, id := range ids {
+		author, ok := found[id]
+		if !ok {
+			return nil, models.ErrAuthorNotFound
+		}
+		if author != nil {
+			authors = append(authors, author)
+			found[id] = nil
+		}
+	}
+	return authors, nil
+}
+
+//FindOrCreate func
+func (r *InitAuthorRepository) FindOrCreate(ctx context.Context, name string) (author *models.Author, err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: the conflicted row is updated to itself so its columns is returned
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Insert(authorTable).
+		Columns(authorNameColumn).
+		Values(name).
+		Suffix(fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s RETURNING %s",
+			authorNameColumn, authorNameColumn, authorNameColumn, strings.Join(AuthorColumns, ", ")))
+
+	author = &models.Author{}
+	if err = builder.RunWith(trxn.DB).QueryRowContext(ctx).Scan(author.Fields()...); err != nil {
+		trxn.SetError(err)
+		return nil, err
+	}
+
+	return author, nil
+}
+
+//SetBookAuthors func
+func (r *InitAuthorRepository) SetBookAuthors(ctx context.Context, bookID int64, authorIDs []int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	_, err = psql.Delete(bookAuthorTable).
+		Where(sq.Eq{bookIDColumn: bookID}).
+		RunWith(trxn.DB).
+		ExecContext(ctx)
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	if len(authorIDs) == 0 {
+		return nil
+	}
+
+	builder := psql.Insert(bookAuthorTable).
+		Columns(bookIDColumn, authorIDColumn, positionColumn).
+		Suffix("ON CONFLICT DO NOTHING")
+	for i, authorID := range authorIDs {
+		builder = builder.Values(bookID, authorID, i)
+	}
+
+	_, err = builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err = authorError(err); err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+// RenameBooks set author name of the books linked to the author after the author is renamed
+func (r *InitAuthorRepository) RenameBooks(ctx context.Context, authorID int64) error {
+	return r.renameBooks(ctx, authorID, 0)
+}
+
+// RemoveFromBooks set author name of the books linked to the author by the rest of their authors before the author is deleted
+func (r *InitAuthorRepository) RemoveFromBooks(ctx context.Context, authorID int64) error {
+	return r.renameBooks(ctx, authorID, authorID)
+}
+
+func (r *InitAuthorRepository) renameBooks(ctx context.Context, authorID, removedID int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	if _, err = trxn.DB.ExecContext(ctx, renameBooksQuery, authorID, removedID, time.Now()); err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+// authorError translate constraint violation error into author error
+func authorError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == uniqueViolation && pqErr.Constraint == authorNameConstraint:
+		return models.ErrDuplicateName
+	case pqErr.Code == foreignKeyViolation && pqErr.Constraint == bookAuthorFKConstraint:
+		return models.ErrAuthorNotFound
+	}
+	return err
+}
@@ -0,0 +1,276 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/author/models"
+	"github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/crud"
+)
+
+func TestAuthorRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	authorRepository := repository.NewAuthorRepository(db)
+	crudRepository := crud.NewRepository(db, repository.AuthorTable)
+
+	t.Run("Insert", func(t *testing.T) {
+		insertSQL := regexp.QuoteMeta(`INSERT INTO authors (name) VALUES ($1) RETURNING "id"`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-name").
+				WillReturnError(fmt.Errorf("some-insert-error"))
+			_, err := crudRepository.Insert(context.Background(), &models.Author{Name: "some-name"})
+			require.EqualError(t, err, "some-insert-error")
+		})
+
+		t.Run("duplicate name", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-name").
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "authors_name_key"})
+			_, err := crudRepository.Insert(context.Background(), &models.Author{Name: "some-name"})
+			require.Equal(t, models.ErrDuplicateName, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-name").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+			id, err := crudRepository.Insert(context.Background(), &models.Author{Name: "some-name"})
+			require.NoError(t, err)
+			require.Equal(t, int64(99), id)
+		})
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		findSQL := regexp.QuoteMeta(`SELECT id, name, updated_at, created_at FROM authors WHERE id = $1`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(findSQL).WithArgs(123).WillReturnError(fmt.Errorf("some-find-error"))
+			_, err := crudRepository.Find(context.Background(), 123)
+			require.EqualError(t, err, "some-find-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(findSQL).WithArgs(123).
+				WillReturnRows(sqlmock.NewRows(repository.AuthorColumns).AddRow(123, "some-name", now, now))
+			author, err := crudRepository.Find(context.Background(), 123)
+			require.NoError(t, err)
+			require.Equal(t, &models.Author{ID: 123, Name: "some-name", UpdatedAt: now, CreatedAt: now}, author)
+		})
+	})
+
+	t.Run("List", func(t *testing.T) {
+		listSQL := regexp.QuoteMeta(`SELECT id, name, updated_at, created_at FROM authors WHERE (name ILIKE $1) ORDER BY name ASC, id ASC LIMIT 10 OFFSET 10`)
+		criteria := crud.Criteria{Search: map[string]string{"name": "tolkien"}, Page: 2, Size: 10}
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(listSQL).WithArgs("%tolkien%").WillReturnError(fmt.Errorf("some-list-error"))
+			_, err := crudRepository.List(context.Background(), criteria)
+			require.EqualError(t, err, "some-list-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(listSQL).WithArgs("%tolkien%").
+				WillReturnRows(sqlmock.NewRows(repository.AuthorColumns).
+					AddRow(1, "J. R. R. Tolkien", now, now).
+					AddRow(2, "Christopher Tolkien", now, now))
+			authors, err := crudRepository.List(context.Background(), criteria)
+			require.NoError(t, err)
+			require.Equal(t, []crud.Entity{
+				&models.Author{ID: 1, Name: "J. R. R. Tolkien", UpdatedAt: now, CreatedAt: now},
+				&models.Author{ID: 2, Name: "Christopher Tolkien", UpdatedAt: now, CreatedAt: now},
+			}, authors)
+		})
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM authors`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+		total, err := crudRepository.Count(context.Background(), crud.Criteria{})
+		require.NoError(t, err)
+		require.Equal(t, int64(42), total)
+	})
+
+	t.Run("ListByBooks", func(t *testing.T) {
+		listSQL := regexp.QuoteMeta(`SELECT ba.book_id, a.id, a.name, a.updated_at, a.created_at FROM book_authors ba ` +
+			`JOIN authors a ON a.id = ba.author_id WHERE ba.book_id IN ($1,$2) ORDER BY ba.book_id, ba.position, a.id`)
+
+		t.Run("no book", func(t *testing.T) {
+			authors, err := authorRepository.ListByBooks(context.TODO(), nil)
+			require.NoError(t, err)
+			require.Empty(t, authors)
+		})
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(listSQL).WithArgs(1, 2).WillReturnError(fmt.Errorf("some-list-error"))
+			_, err := authorRepository.ListByBooks(context.TODO(), []int64{1, 2})
+			require.EqualError(t, err, "some-list-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(listSQL).WithArgs(1, 2).
+				WillReturnRows(sqlmock.NewRows(append([]string{"book_id"}, repository.AuthorColumns...)).
+					AddRow(1, 10, "some-name1", now, now).
+					AddRow(1, 11, "some-name2", now, now).
+					AddRow(2, 10, "some-name1", now, now))
+			authors, err := authorRepository.ListByBooks(context.TODO(), []int64{1, 2})
+			require.NoError(t, err)
+			require.Equal(t, map[int64][]*models.Author{
+				1: {
+					{ID: 10, Name: "some-name1", UpdatedAt: now, CreatedAt: now},
+					{ID: 11, Name: "some-name2", UpdatedAt: now, CreatedAt: now},
+				},
+				2: {
+					{ID: 10, Name: "some-name1", UpdatedAt: now, CreatedAt: now},
+				},
+			}, authors)
+		})
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updateSQL := regexp.QuoteMeta(`UPDATE authors SET name = $1, updated_at = $2 WHERE id = $3`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("some-name", sqlmock.AnyArg(), 123).
+				WillReturnError(fmt.Errorf("some-update-error"))
+			err := crudRepository.Update(context.Background(), &models.Author{ID: 123, Name: "some-name"})
+			require.EqualError(t, err, "some-update-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("some-name", sqlmock.AnyArg(), 123).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			err := crudRepository.Update(context.Background(), &models.Author{ID: 123, Name: "some-name"})
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleteSQL := regexp.QuoteMeta(`DELETE FROM authors WHERE id = $1`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(123).WillReturnError(fmt.Errorf("some-delete-error"))
+			err := crudRepository.Delete(context.Background(), 123)
+			require.EqualError(t, err, "some-delete-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(123).WillReturnResult(sqlmock.NewResult(1, 1))
+			err := crudRepository.Delete(context.Background(), 123)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("FindByIDs", func(t *testing.T) {
+		findSQL := regexp.QuoteMeta(`SELECT id, name, updated_at, created_at FROM authors WHERE id IN ($1,$2,$3)`)
+		now := time.Now()
+
+		t.Run("author not found", func(t *testing.T) {
+			mock.ExpectQuery(findSQL).WithArgs(11, 10, 11).
+				WillReturnRows(sqlmock.NewRows(repository.AuthorColumns).AddRow(10, "some-name10", now, now))
+			_, err := authorRepository.FindByIDs(context.Background(), []int64{11, 10, 11})
+			require.Equal(t, models.ErrAuthorNotFound, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(findSQL).WithArgs(11, 10, 11).
+				WillReturnRows(sqlmock.NewRows(repository.AuthorColumns).
+					AddRow(10, "some-name10", now, now).
+					AddRow(11, "some-name11", now, now))
+			authors, err := authorRepository.FindByIDs(context.Background(), []int64{11, 10, 11})
+			require.NoError(t, err)
+			require.Equal(t, []*models.Author{
+				{ID: 11, Name: "some-name11", UpdatedAt: now, CreatedAt: now},
+				{ID: 10, Name: "some-name10", UpdatedAt: now, CreatedAt: now},
+			}, authors)
+		})
+	})
+
+	t.Run("FindOrCreate", func(t *testing.T) {
+		upsertSQL := regexp.QuoteMeta(`INSERT INTO authors (name) VALUES ($1) ` +
+			`ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id, name, updated_at, created_at`)
+		now := time.Now()
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(upsertSQL).WithArgs("some-name").WillReturnError(fmt.Errorf("some-upsert-error"))
+			_, err := authorRepository.FindOrCreate(context.Background(), "some-name")
+			require.EqualError(t, err, "some-upsert-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(upsertSQL).WithArgs("some-name").
+				WillReturnRows(sqlmock.NewRows(repository.AuthorColumns).AddRow(12, "some-name", now, now))
+			author, err := authorRepository.FindOrCreate(context.Background(), "some-name")
+			require.NoError(t, err)
+			require.Equal(t, &models.Author{ID: 12, Name: "some-name", UpdatedAt: now, CreatedAt: now}, author)
+		})
+	})
+
+	t.Run("SetBookAuthors", func(t *testing.T) {
+		deleteSQL := regexp.QuoteMeta(`DELETE FROM book_authors WHERE book_id = $1`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnError(fmt.Errorf("some-delete-error"))
+			err := authorRepository.SetBookAuthors(context.Background(), 1, []int64{10})
+			require.EqualError(t, err, "some-delete-error")
+		})
+
+		t.Run("author not found", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO book_authors`)).
+				WillReturnError(&pq.Error{Code: "23503", Constraint: "book_authors_author_id_fkey"})
+			err := authorRepository.SetBookAuthors(context.Background(), 1, []int64{404})
+			require.Equal(t, models.ErrAuthorNotFound, err)
+		})
+
+		t.Run("clear authors", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+			err := authorRepository.SetBookAuthors(context.Background(), 1, []int64{})
+			require.NoError(t, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO book_authors (book_id,author_id,position) VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT DO NOTHING`)).
+				WithArgs(1, 11, 0, 1, 10, 1).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			err := authorRepository.SetBookAuthors(context.Background(), 1, []int64{11, 10})
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("RenameBooks", func(t *testing.T) {
+		renameSQL := regexp.QuoteMeta(`UPDATE books b SET author = n.author, version = b.version + 1, updated_at = $3`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(renameSQL).WithArgs(12, 0, sqlmock.AnyArg()).WillReturnError(fmt.Errorf("some-rename-error"))
+			err := authorRepository.RenameBooks(context.Background(), 12)
+			require.EqualError(t, err, "some-rename-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(renameSQL).WithArgs(12, 0, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+			err := authorRepository.RenameBooks(context.Background(), 12)
+			require.NoError(t, err)
+		})
+
+		t.Run("remove from books", func(t *testing.T) {
+			mock.ExpectExec(renameSQL).WithArgs(12, 12, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+			err := authorRepository.RemoveFromBooks(context.Background(), 12)
+			require.NoError(t, err)
+		})
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
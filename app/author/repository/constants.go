@@ -0,0 +1,48 @@
+package repository
+
+// Table Name
+const (
+	authorTable     = "authors"
+	bookAuthorTable = "book_authors"
+)
+
+// Table Column Names
+const (
+	idColumn        = "id"
+	updatedAtColumn = "updated_at"
+	createdAtColumn = "created_at"
+
+	// Author Table Column Names
+	authorNameColumn = "name"
+
+	// Book Author Table Column Names
+	bookIDColumn   = "book_id"
+	authorIDColumn = "author_id"
+	positionColumn = "position"
+)
+
+// Constraint Names
+const (
+	authorNameConstraint   = "authors_name_key"
+	bookAuthorFKConstraint = "book_authors_author_id_fkey"
+
+	// postgres error code when unique or foreign key constraint is violated
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// renameBooksQuery set author of the books linked to author $1 as names of their linked authors in order; author $2 is left out
+// since it is going to be deleted. Version of the renamed book is increased so its entity tag is changed
+const renameBooksQuery = `UPDATE books b SET author = n.author, version = b.version + 1, updated_at = $3
+FROM (
+SELECT ba.book_id, COALESCE(string_agg(a.name, ', ' ORDER BY ba.position, a.id) FILTER (WHERE a.id <> $2), '') AS author
+FROM book_authors ba JOIN authors a ON a.id = ba.author_id
+WHERE ba.book_id IN (SELECT book_id FROM book_authors WHERE author_id = $1)
+GROUP BY ba.book_id
+) n
+WHERE b.id = n.book_id AND b.author <> n.author`
+
+// Table Columns
+var (
+	AuthorColumns = []string{idColumn, authorNameColumn, updatedAtColumn, createdAtColumn}
+)
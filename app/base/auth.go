@@ -0,0 +1,58 @@
+package base
+
+import (
+	"errors"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+// claimsKey is context key of the claims of authenticated request
+const claimsKey = "auth.claims"
+
+// Authenticate verify bearer token of the request and put its claims into echo context and request context; the subject of
+// the token become the actor of the request. The request is not authenticated when the skipper return true
+func Authenticate(verifier *jwtauth.Verifier, skipper middleware.Skipper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if skipper(ctx) {
+				return next(ctx)
+			}
+
+			token, err := jwtauth.BearerToken(ctx.Request().Header.Get(echo.HeaderAuthorization))
+			if err == nil {
+				var claims jwtauth.Claims
+				if claims, err = verifier.Verify(token); err == nil {
+					return next(withClaims(ctx, claims))
+				}
+			}
+
+			// NOTE: challenge the client as described in RFC 6750; invalid token is told apart from missing token
+			challenge := "Bearer"
+			if !errors.Is(err, jwtauth.ErrMissingToken) {
+				challenge += ` error="invalid_token"`
+			}
+			ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+			return NewUnauthorizedError(err)
+		}
+	}
+}
+
+// Claims return claims of the authenticated request; nil when the request is not authenticated
+func Claims(ctx echo.Context) jwtauth.Claims {
+	claims, _ := ctx.Get(claimsKey).(jwtauth.Claims)
+	return claims
+}
+
+func withClaims(ctx echo.Context, claims jwtauth.Claims) echo.Context {
+	ctx.Set(claimsKey, claims)
+
+	reqCtx := ctxkit.WithClaims(ctx.Request().Context(), claims)
+	if subject := claims.Subject(); subject != "" {
+		reqCtx = ctxkit.WithActor(reqCtx, subject)
+	}
+	ctx.SetRequest(ctx.Request().WithContext(reqCtx))
+	return ctx
+}
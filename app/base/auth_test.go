@@ -0,0 +1,71 @@
+package base_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+func TestAuthenticate(t *testing.T) {
+	secret := []byte("some-secret")
+	authenticate := base.Authenticate(jwtauth.New(jwtauth.Config{Secret: secret}), func(ctx echo.Context) bool {
+		return ctx.Request().Method == http.MethodGet
+	})
+	handle := func(authorization, method string) (echo.Context, *httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/book", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		var handled echo.Context
+		err := authenticate(func(ctx echo.Context) error {
+			handled = ctx
+			return nil
+		})(echo.New().NewContext(req, rec))
+		return handled, rec, err
+	}
+
+	t.Run("authenticated", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "some-subject",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString(secret)
+		require.NoError(t, err)
+
+		ctx, _, err := handle("Bearer "+token, http.MethodPost)
+		require.NoError(t, err)
+		require.Equal(t, "some-subject", base.Claims(ctx).Subject())
+		require.Equal(t, "some-subject", ctxkit.Claims(ctx.Request().Context()).Subject())
+		require.Equal(t, "some-subject", ctxkit.Actor(ctx.Request().Context()))
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		_, rec, err := handle("", http.MethodPost)
+		appErr, ok := base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnauthorized, appErr.Status)
+		require.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, rec, err := handle("Bearer some-token", http.MethodPost)
+		appErr, ok := base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusUnauthorized, appErr.Status)
+		require.Equal(t, `Bearer error="invalid_token"`, rec.Header().Get(echo.HeaderWWWAuthenticate))
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		ctx, _, err := handle("", http.MethodGet)
+		require.NoError(t, err)
+		require.Nil(t, base.Claims(ctx))
+	})
+}
@@ -1,6 +1,7 @@
 package base
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -9,27 +10,21 @@ import (
 
 const (
 	underConstrucionStatus = http.StatusServiceUnavailable
-	invalidMessageStatus   = http.StatusBadRequest
-	invalidIDStatus        = http.StatusBadRequest
 	insertSuccessStatus    = http.StatusCreated
 )
 
-func invalidMessage(ctx echo.Context, err error) error {
-	res := map[string]interface{}{}
-	res["message"] = "Invalid Message"
-
-	return ctx.JSON(invalidMessageStatus, res)
-}
-
-func invalidID(ctx echo.Context, err error) error {
-	res := map[string]interface{}{}
-	res["message"] = "Invalid ID"
-
-	return ctx.JSON(invalidIDStatus, res)
+// InvalidID return error of malformed id
+func InvalidID(err error) error {
+	if err == nil {
+		return NewValidationError(errors.New("invalid id"))
+	}
+	return NewValidationError(fmt.Errorf("invalid id: %w", err))
 }
 
-func insertSuccess(ctx echo.Context, lastInsertID int64) error {
+// InsertSuccess response of created record
+func InsertSuccess(ctx echo.Context, lastInsertID int64) error {
 	res := map[string]interface{}{}
 	res["message"] = fmt.Sprintf("Success insert new record #%d", lastInsertID)
-	return ctx.JSON(insertSuccessStatus, res)
+	res["data"] = lastInsertID
+	return Render(ctx, insertSuccessStatus, res)
 }
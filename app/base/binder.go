@@ -0,0 +1,46 @@
+package base
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/helper/mediakit"
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+// Binder decode request body by its Content-Type; XML, MessagePack and JSON:API body follow the JSON field names like the response
+type Binder struct {
+	echo.DefaultBinder
+}
+
+// Bind request body into i
+func (b *Binder) Bind(i interface{}, ctx echo.Context) (err error) {
+	req := ctx.Request()
+	ctype := req.Header.Get(echo.HeaderContentType)
+	if req.ContentLength == 0 {
+		return b.DefaultBinder.Bind(i, ctx)
+	}
+
+	switch {
+	case strings.HasPrefix(ctype, MIMEApplicationMsgpack), strings.HasPrefix(ctype, MIMEApplicationXMsgpack):
+		dec := msgpack.NewDecoder(req.Body).UseJSONTag(true)
+		if err = dec.Decode(i); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	case strings.HasPrefix(ctype, echo.MIMEApplicationXML), strings.HasPrefix(ctype, echo.MIMETextXML):
+		var body []byte
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return err
+		}
+		if err = mediakit.UnmarshalXML(body, i); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	case strings.HasPrefix(ctype, MIMEApplicationJSONAPI):
+		return bindJSONAPI(i, ctx)
+	default:
+		return b.DefaultBinder.Bind(i, ctx)
+	}
+	return
+}
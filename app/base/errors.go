@@ -0,0 +1,103 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Problem type of application error; about:blank has no semantic beyond its status code
+const (
+	ProblemBlank        = "about:blank"
+	ProblemValidation   = "urn:problem-type:validation"
+	ProblemNotFound     = "urn:problem-type:not-found"
+	ProblemConflict     = "urn:problem-type:conflict"
+	ProblemUnauthorized = "urn:problem-type:unauthorized"
+)
+
+// Error is application error which is rendered as problem details
+type Error struct {
+	Type   string
+	Title  string
+	Status int
+	Detail string
+	// Err is the cause of the error
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// Unwrap return the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError return application error of the status code
+func NewError(status int, err error) *Error {
+	return &Error{
+		Type:   ProblemBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail(err, http.StatusText(status)),
+		Err:    err,
+	}
+}
+
+// NewValidationError return error of invalid request
+func NewValidationError(err error) *Error {
+	return &Error{
+		Type:   ProblemValidation,
+		Title:  "Invalid Request",
+		Status: http.StatusBadRequest,
+		Detail: detail(err, "invalid request"),
+		Err:    err,
+	}
+}
+
+// NewNotFoundError return error of missing resource
+func NewNotFoundError(format string, args ...interface{}) *Error {
+	return &Error{
+		Type:   ProblemNotFound,
+		Title:  "Resource Not Found",
+		Status: http.StatusNotFound,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+// NewConflictError return error of request which conflict with current state of the resource
+func NewConflictError(err error) *Error {
+	return &Error{
+		Type:   ProblemConflict,
+		Title:  "Resource Conflict",
+		Status: http.StatusConflict,
+		Detail: detail(err, "resource conflict"),
+		Err:    err,
+	}
+}
+
+// NewUnauthorizedError return error of request without valid credential
+func NewUnauthorizedError(err error) *Error {
+	return &Error{
+		Type:   ProblemUnauthorized,
+		Title:  "Unauthorized",
+		Status: http.StatusUnauthorized,
+		Detail: detail(err, "missing or invalid credential"),
+		Err:    err,
+	}
+}
+
+// AsError return application error in the chain of the error
+func AsError(err error) (*Error, bool) {
+	var appErr *Error
+	ok := errors.As(err, &appErr)
+	return appErr, ok
+}
+
+func detail(err error, defaultDetail string) string {
+	if err == nil {
+		return defaultDetail
+	}
+	return err.Error()
+}
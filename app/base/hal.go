@@ -0,0 +1,177 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// MIMEApplicationHAL is media type of Hypertext Application Language
+const MIMEApplicationHAL = "application/hal+json"
+
+// Action of CRUD route; the route is named as "<entity>.<action>" to be reversed into hypermedia link
+const (
+	ActionList   = "list"
+	ActionCreate = "create"
+	ActionGet    = "get"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// halItem is relation of the items embedded in HAL collection (RFC 6573)
+const halItem = "item"
+
+// RouteName return name of the entity route
+func RouteName(entity, action string) string {
+	return fmt.Sprintf("%s.%s", entity, action)
+}
+
+// Link is hypermedia reference of the HAL resource
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links of HAL resource by its relation; value is Link or list of Link
+type Links map[string]interface{}
+
+// Set the link of the relation; empty href of unregistered route is ignored
+func (l Links) Set(rel, href string) {
+	if href != "" {
+		l[rel] = Link{Href: href}
+	}
+}
+
+// Append the link to list of the relation; empty href of unregistered route is ignored
+func (l Links) Append(rel, href string) {
+	if href != "" {
+		links, _ := l[rel].([]Link)
+		l[rel] = append(links, Link{Href: href})
+	}
+}
+
+// Resource wrap the data with its hypermedia links; the links is only rendered in HAL and JSON:API format
+type Resource struct {
+	Data  interface{}
+	Links Links
+}
+
+// PageLinks return self, first, last, prev and next link of page-based listing at the path
+func PageLinks(path string, query url.Values, meta Pagination) Links {
+	links := make(Links)
+	if path == "" {
+		return links
+	}
+	page := func(page int64) string {
+		return pageHref(path, query, "page", strconv.FormatInt(page, 10))
+	}
+	links.Set("self", page(int64(meta.Page)))
+	links.Set("first", page(1))
+	if meta.TotalPages > 0 {
+		links.Set("last", page(meta.TotalPages))
+	}
+	if meta.Page > 1 {
+		links.Set("prev", page(int64(meta.Page)-1))
+	}
+	if int64(meta.Page) < meta.TotalPages {
+		links.Set("next", page(int64(meta.Page)+1))
+	}
+	return links
+}
+
+// CursorLinks return self and next link of keyset listing at the path; there is no next link at the last page
+func CursorLinks(path string, query url.Values, meta CursorPagination) Links {
+	links := make(Links)
+	if path == "" {
+		return links
+	}
+	links.Set("self", pageHref(path, query, "cursor", query.Get("cursor")))
+	if meta.NextCursor != "" {
+		links.Set("next", pageHref(path, query, "cursor", meta.NextCursor))
+	}
+	return links
+}
+
+func pageHref(path string, query url.Values, param, value string) string {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set(param, value)
+	return path + "?" + q.Encode()
+}
+
+// halData return the data in HAL form; links is rendered as "_links" and items of list as "_embedded"
+func halData(data interface{}) (interface{}, error) {
+	switch v := data.(type) {
+	case Resource:
+		raws, err := objectFields(v.Data)
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]interface{}, len(raws)+1)
+		for k, raw := range raws {
+			fields[k] = raw
+		}
+		if len(v.Links) > 0 {
+			fields["_links"] = v.Links
+		}
+		return fields, nil
+	case ListResponse:
+		items := make([]interface{}, 0)
+		if v.Data != nil {
+			value := reflect.ValueOf(v.Data)
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				return nil, fmt.Errorf("list data must be slice, not %T", v.Data)
+			}
+			for i := 0; i < value.Len(); i++ {
+				item, err := halData(value.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+		}
+		fields := map[string]interface{}{
+			"_embedded": map[string]interface{}{halItem: items},
+			"meta":      v.Meta,
+		}
+		if len(v.Links) > 0 {
+			fields["_links"] = v.Links
+		}
+		return fields, nil
+	}
+	return data, nil
+}
+
+// objectFields return JSON object of the data to be merged with links or split into attributes
+func objectFields(data interface{}) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("resource must be JSON object: %w", err)
+	}
+	return fields, nil
+}
+
+// plainData return the data without hypermedia links for non-HAL format
+func plainData(data interface{}) interface{} {
+	switch v := data.(type) {
+	case Resource:
+		return v.Data
+	case ListResponse:
+		if resources, ok := v.Data.([]Resource); ok {
+			items := make([]interface{}, len(resources))
+			for i, resource := range resources {
+				items[i] = resource.Data
+			}
+			v.Data = items
+		}
+		return v
+	}
+	return data
+}
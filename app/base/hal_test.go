@@ -0,0 +1,105 @@
+package base_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+)
+
+func TestPageLinks(t *testing.T) {
+	query := url.Values{"title": {"go"}, "page": {"2"}}
+	require.Equal(t, base.Links{
+		"self":  base.Link{Href: "/book?page=2&title=go"},
+		"first": base.Link{Href: "/book?page=1&title=go"},
+		"last":  base.Link{Href: "/book?page=3&title=go"},
+		"prev":  base.Link{Href: "/book?page=1&title=go"},
+		"next":  base.Link{Href: "/book?page=3&title=go"},
+	}, base.PageLinks("/book", query, base.Pagination{Page: 2, Size: 20, Total: 50, TotalPages: 3}))
+
+	require.Equal(t, base.Links{
+		"self":  base.Link{Href: "/book?page=1"},
+		"first": base.Link{Href: "/book?page=1"},
+	}, base.PageLinks("/book", url.Values{}, base.Pagination{Page: 1, Size: 20}))
+
+	require.Empty(t, base.PageLinks("", query, base.Pagination{Page: 1}))
+}
+
+func TestCursorLinks(t *testing.T) {
+	require.Equal(t, base.Links{
+		"self": base.Link{Href: "/book?cursor=abc"},
+		"next": base.Link{Href: "/book?cursor=def"},
+	}, base.CursorLinks("/book", url.Values{"cursor": {"abc"}}, base.CursorPagination{Size: 20, NextCursor: "def"}))
+
+	require.Equal(t, base.Links{
+		"self": base.Link{Href: "/book?cursor="},
+	}, base.CursorLinks("/book", url.Values{"cursor": {""}}, base.CursorPagination{Size: 20}))
+}
+
+func TestLinks(t *testing.T) {
+	links := make(base.Links)
+	links.Set("self", "/author/1")
+	links.Set("collection", "")
+	links.Append("books", "/book/1")
+	links.Append("books", "/book/2")
+	links.Append("categories", "")
+	require.Equal(t, base.Links{
+		"self":  base.Link{Href: "/author/1"},
+		"books": []base.Link{{Href: "/book/1"}, {Href: "/book/2"}},
+	}, links)
+}
+
+func TestRender_HAL(t *testing.T) {
+	resource := base.Resource{
+		Data:  author{ID: 1, Name: "some-name"},
+		Links: base.Links{"self": base.Link{Href: "/author/1"}},
+	}
+	list := base.ListResponse{
+		Data:  []base.Resource{resource},
+		Meta:  base.CursorPagination{Size: 20},
+		Links: base.Links{"self": base.Link{Href: "/author"}},
+	}
+	testcases := []struct {
+		accept      string
+		data        interface{}
+		contentType string
+		expected    string
+	}{
+		{
+			accept:      "application/hal+json",
+			data:        resource,
+			contentType: base.MIMEApplicationHAL,
+			expected:    `{"_links":{"self":{"href":"/author/1"}},"id":1,"name":"some-name"}` + "\n",
+		},
+		{
+			accept:      "application/hal+json",
+			data:        list,
+			contentType: base.MIMEApplicationHAL,
+			expected: `{"_embedded":{"item":[{"_links":{"self":{"href":"/author/1"}},"id":1,"name":"some-name"}]},` +
+				`"_links":{"self":{"href":"/author"}},"meta":{"size":20}}` + "\n",
+		},
+		{
+			data:        resource,
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			expected:    `{"id":1,"name":"some-name"}` + "\n",
+		},
+		{
+			data:        list,
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			expected:    `{"data":[{"id":1,"name":"some-name"}],"meta":{"size":20}}` + "\n",
+		},
+	}
+
+	for _, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAccept, tt.accept)
+		rec := httptest.NewRecorder()
+		require.NoError(t, base.Render(echo.New().NewContext(req, rec), http.StatusOK, tt.data))
+		require.Equal(t, tt.contentType, rec.Header().Get(echo.HeaderContentType), tt.accept)
+		require.Equal(t, tt.expected, rec.Body.String(), tt.accept)
+	}
+}
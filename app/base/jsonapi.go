@@ -0,0 +1,389 @@
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// MIMEApplicationJSONAPI is media type of JSON:API document
+const MIMEApplicationJSONAPI = "application/vnd.api+json"
+
+// jsonapiKey is context key of the JSON:API resource of the route
+const jsonapiKey = "jsonapi.resource"
+
+// Relater is controller whose resource has relationships in JSON:API document
+type Relater interface {
+	// Relationships map JSON field of the related resources to its resource type (e.g. "authors" to "author")
+	Relationships() map[string]string
+}
+
+// jsonapiType of the route resource
+type jsonapiType struct {
+	name          string
+	relationships map[string]string
+}
+
+// JSONAPI middleware mark the route as resource of the type which can be rendered as JSON:API document;
+// the page[...], filter[...], fields[...] and include query parameters are mapped into the parameters of the route
+func JSONAPI(resourceType string, relationships map[string]string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set(jsonapiKey, jsonapiType{name: resourceType, relationships: relationships})
+			jsonapiQuery(ctx, resourceType)
+			return next(ctx)
+		}
+	}
+}
+
+// jsonapiQuery rewrite JSON:API query parameters into the plain one; e.g. "page[number]" into "page" and "filter[name_like]" into "name_like"
+func jsonapiQuery(ctx echo.Context, resourceType string) {
+	query := ctx.QueryParams()
+	mapped := false
+	for key, values := range query {
+		open := strings.Index(key, "[")
+		if key == "include" {
+			query["expand"] = values
+		} else if open > 0 && strings.HasSuffix(key, "]") {
+			family, member := key[:open], key[open+1:len(key)-1]
+			switch {
+			case family == "page" && member == "number":
+				query["page"] = values
+			case family == "page" && (member == "size" || member == "cursor"):
+				query[member] = values
+			case family == "filter":
+				query[member] = values
+			case family == "fields" && member == resourceType:
+				query["fields"] = values
+			default:
+				continue
+			}
+		} else {
+			continue
+		}
+		delete(query, key)
+		mapped = true
+	}
+	if mapped {
+		// NOTE: the cached query params is updated in place so handler read the mapped parameters either way
+		ctx.Request().URL.RawQuery = query.Encode()
+	}
+}
+
+// jsonapiResource is resource object of JSON:API document
+type jsonapiResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]json.RawMessage     `json:"attributes,omitempty"`
+	Relationships map[string]jsonapiRelationship `json:"relationships,omitempty"`
+	Links         map[string]Link                `json:"links,omitempty"`
+}
+
+// jsonapiIdentifier identify the related resource
+type jsonapiIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// jsonapiRelationship is linkage to the related resources; data is list of identifier for to-many relationship
+type jsonapiRelationship struct {
+	Data interface{} `json:"data"`
+}
+
+// jsonapiDocument is top-level of JSON:API document
+type jsonapiDocument struct {
+	Data     interface{}       `json:"data,omitempty"`
+	Included []jsonapiResource `json:"included,omitempty"`
+	Meta     interface{}       `json:"meta,omitempty"`
+	Links    map[string]Link   `json:"links,omitempty"`
+}
+
+// jsonapiError is error object of JSON:API document
+type jsonapiError struct {
+	ID     string              `json:"id,omitempty"`
+	Status string              `json:"status"`
+	Code   string              `json:"code,omitempty"`
+	Title  string              `json:"title"`
+	Detail string              `json:"detail,omitempty"`
+	Source *jsonapiErrorSource `json:"source,omitempty"`
+}
+
+// jsonapiErrorSource point the invalid member of request document
+type jsonapiErrorSource struct {
+	Pointer string `json:"pointer"`
+}
+
+// JSONAPIErrors return JSON:API document of the problem; each invalid field is an error object
+func JSONAPIErrors(p *Problem) interface{} {
+	status := strconv.Itoa(p.Status)
+	var errs []jsonapiError
+	for _, fieldErr := range p.Errors {
+		errs = append(errs, jsonapiError{
+			ID:     p.RequestID,
+			Status: status,
+			Code:   fieldErr.Rule,
+			Title:  p.Title,
+			Detail: fieldErr.Message,
+			Source: &jsonapiErrorSource{Pointer: "/data/attributes/" + strings.NewReplacer(".", "/", "[", "/", "]", "").Replace(fieldErr.Field)},
+		})
+	}
+	if len(errs) == 0 {
+		errs = append(errs, jsonapiError{ID: p.RequestID, Status: status, Title: p.Title, Detail: p.Detail})
+	}
+	return map[string]interface{}{"errors": errs}
+}
+
+// IsJSONAPI return whether the response is negotiated as JSON:API document
+func IsJSONAPI(ctx echo.Context) bool {
+	format, _ := Negotiate(ctx)
+	return format == FormatJSONAPI
+}
+
+// jsonapiData return JSON:API document of the data; data without id such as message is rendered as meta
+func jsonapiData(ctx echo.Context, data interface{}) (interface{}, error) {
+	resourceType, ok := ctx.Get(jsonapiKey).(jsonapiType)
+	if !ok {
+		return nil, notAcceptable("JSON:API is only supported by resource route")
+	}
+	builder := &jsonapiBuilder{jsonapiType: resourceType, included: make(map[jsonapiIdentifier]bool)}
+	doc := jsonapiDocument{}
+
+	if list, ok := data.(ListResponse); ok {
+		resources := make([]jsonapiResource, 0)
+		if list.Data != nil {
+			value := reflect.ValueOf(list.Data)
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				return nil, fmt.Errorf("list data must be slice, not %T", list.Data)
+			}
+			for i := 0; i < value.Len(); i++ {
+				resource, ok, err := builder.resource(value.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					resources = append(resources, resource)
+				}
+			}
+		}
+		doc.Data = resources
+		doc.Meta = list.Meta
+		doc.Links = jsonapiLinks(list.Links)
+	} else {
+		resource, ok, err := builder.resource(data)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			doc.Data = resource
+		} else {
+			doc.Meta = plainData(data)
+		}
+	}
+	doc.Included = builder.resources
+	return doc, nil
+}
+
+// jsonapiBuilder build resource objects and collect the related resources into included
+type jsonapiBuilder struct {
+	jsonapiType
+	included  map[jsonapiIdentifier]bool
+	resources []jsonapiResource
+}
+
+// resource return resource object of the data; false when the data has no id
+func (b *jsonapiBuilder) resource(data interface{}) (resource jsonapiResource, ok bool, err error) {
+	if r, isResource := data.(Resource); isResource {
+		resource.Links = jsonapiLinks(r.Links)
+		data = r.Data
+	}
+	attributes, err := objectFields(data)
+	if err != nil {
+		return resource, false, nil
+	}
+	id, ok := jsonapiID(attributes["id"])
+	if !ok {
+		return resource, false, nil
+	}
+	delete(attributes, "id")
+
+	resource.Type, resource.ID, resource.Attributes = b.name, id, attributes
+	for _, field := range b.relationshipFields() {
+		raw, exist := attributes[field]
+		if !exist {
+			continue
+		}
+		delete(attributes, field)
+		if resource.Relationships == nil {
+			resource.Relationships = make(map[string]jsonapiRelationship)
+		}
+		if resource.Relationships[field], err = b.relationship(b.relationships[field], raw); err != nil {
+			return resource, false, err
+		}
+	}
+	return resource, true, nil
+}
+
+// relationshipFields return field of the relationships in sorted order so included is rendered in stable order
+func (b *jsonapiBuilder) relationshipFields() []string {
+	fields := make([]string, 0, len(b.relationships))
+	for field := range b.relationships {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// relationship return linkage of the related resources which is added into included
+func (b *jsonapiBuilder) relationship(relatedType string, raw json.RawMessage) (jsonapiRelationship, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if bytes.Equal(trimmed, []byte("null")) {
+		return jsonapiRelationship{}, nil
+	}
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		identifier, err := b.include(relatedType, trimmed)
+		return jsonapiRelationship{Data: identifier}, err
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(trimmed, &raws); err != nil {
+		return jsonapiRelationship{}, err
+	}
+	identifiers := make([]jsonapiIdentifier, len(raws))
+	for i, related := range raws {
+		identifier, err := b.include(relatedType, related)
+		if err != nil {
+			return jsonapiRelationship{}, err
+		}
+		identifiers[i] = identifier
+	}
+	return jsonapiRelationship{Data: identifiers}, nil
+}
+
+// include add the related resource into included once
+func (b *jsonapiBuilder) include(relatedType string, raw json.RawMessage) (identifier jsonapiIdentifier, err error) {
+	var attributes map[string]json.RawMessage
+	if err = json.Unmarshal(raw, &attributes); err != nil {
+		return identifier, err
+	}
+	id, ok := jsonapiID(attributes["id"])
+	if !ok {
+		return identifier, fmt.Errorf("related %s has no id", relatedType)
+	}
+	delete(attributes, "id")
+
+	identifier = jsonapiIdentifier{Type: relatedType, ID: id}
+	if !b.included[identifier] {
+		b.included[identifier] = true
+		b.resources = append(b.resources, jsonapiResource{Type: relatedType, ID: id, Attributes: attributes})
+	}
+	return identifier, nil
+}
+
+// jsonapiID return the raw id as string; false when the id is missing
+func jsonapiID(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", false
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id, true
+	}
+	return string(raw), true
+}
+
+// jsonapiLinks return the links which has single target; list of links is represented by relationships instead
+func jsonapiLinks(links Links) map[string]Link {
+	if len(links) == 0 {
+		return nil
+	}
+	single := make(map[string]Link)
+	for rel, link := range links {
+		if l, ok := link.(Link); ok {
+			single[rel] = l
+		}
+	}
+	return single
+}
+
+// bindJSONAPI decode resource object of JSON:API document into i; the id is converted into number when it is numeric
+// and to-many relationship of the type is decoded as "<type>_ids" (e.g. "author_ids")
+func bindJSONAPI(i interface{}, ctx echo.Context) error {
+	body, err := ioutil.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return err
+	}
+	var doc struct {
+		Data *struct {
+			Type          string                     `json:"type"`
+			ID            string                     `json:"id"`
+			Attributes    map[string]json.RawMessage `json:"attributes"`
+			Relationships map[string]struct {
+				Data json.RawMessage `json:"data"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &doc); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	if doc.Data == nil {
+		return NewValidationError(errors.New("data is required"))
+	}
+	resourceType, _ := ctx.Get(jsonapiKey).(jsonapiType)
+	if resourceType.name != "" && doc.Data.Type != resourceType.name {
+		return NewConflictError(fmt.Errorf("type '%s' does not match the endpoint type '%s'", doc.Data.Type, resourceType.name))
+	}
+
+	fields := doc.Data.Attributes
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	if doc.Data.ID != "" {
+		fields["id"] = jsonapiRawID(doc.Data.ID)
+	}
+	for name, relationship := range doc.Data.Relationships {
+		// NOTE: to-one relationship is not supported
+		var identifiers []jsonapiIdentifier
+		if err = json.Unmarshal(relationship.Data, &identifiers); err != nil || identifiers == nil {
+			continue
+		}
+		relatedType, ok := resourceType.relationships[name]
+		if !ok && len(identifiers) > 0 {
+			relatedType = identifiers[0].Type
+		}
+		if relatedType == "" {
+			continue
+		}
+		ids := make([]json.RawMessage, len(identifiers))
+		for j, identifier := range identifiers {
+			ids[j] = jsonapiRawID(identifier.ID)
+		}
+		if fields[relatedType+"_ids"], err = json.Marshal(ids); err != nil {
+			return err
+		}
+	}
+
+	if body, err = json.Marshal(fields); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(body, i); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+func jsonapiRawID(id string) json.RawMessage {
+	if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return json.RawMessage(id)
+	}
+	b, _ := json.Marshal(id)
+	return b
+}
@@ -0,0 +1,148 @@
+package base_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+)
+
+type category struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type book struct {
+	ID         int64       `json:"id"`
+	Title      string      `json:"title"`
+	Authors    []author    `json:"authors,omitempty"`
+	Categories []*category `json:"categories,omitempty"`
+	AuthorIDs  []int64     `json:"author_ids,omitempty"`
+}
+
+var bookRelationships = map[string]string{"authors": "author", "categories": "category"}
+
+func TestJSONAPI_Query(t *testing.T) {
+	e := echo.New()
+	var query string
+	e.GET("/book", func(ctx echo.Context) error {
+		query = ctx.QueryParams().Encode()
+		require.Equal(t, query, ctx.Request().URL.RawQuery)
+		return nil
+	}, base.JSONAPI("book", bookRelationships))
+
+	req := httptest.NewRequest(http.MethodGet, "/book?page[number]=2&page[size]=10&filter[title_like]=go&"+
+		"fields[book]=title&fields[author]=name&include=authors&sort=-title", nil)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, "expand=authors&fields=title&fields%5Bauthor%5D=name&page=2&size=10&sort=-title&title_like=go", query)
+}
+
+func TestRender_JSONAPI(t *testing.T) {
+	e := echo.New()
+	render := func(data interface{}) func(echo.Context) error {
+		return func(ctx echo.Context) error {
+			return base.Render(ctx, http.StatusOK, data)
+		}
+	}
+	jsonapi := base.JSONAPI("book", bookRelationships)
+	shared := &category{ID: 3, Name: "some-category"}
+	e.GET("/book/1", render(base.Resource{
+		Data: book{ID: 1, Title: "some-title", Authors: []author{{ID: 2, Name: "some-name"}}, Categories: []*category{shared}},
+		Links: base.Links{
+			"self":    base.Link{Href: "/book/1"},
+			"authors": []base.Link{{Href: "/author/2"}},
+		},
+	}), jsonapi)
+	e.GET("/book", render(base.ListResponse{
+		Data: []book{
+			{ID: 1, Title: "some-title", Categories: []*category{shared}},
+			{ID: 2, Title: "other-title", Categories: []*category{shared}},
+		},
+		Meta:  base.Pagination{Page: 1, Size: 20, Total: 2, TotalPages: 1},
+		Links: base.Links{"self": base.Link{Href: "/book?page=1"}},
+	}), jsonapi)
+	e.DELETE("/book/1", render(map[string]string{"message": "Delete #1 done"}), jsonapi)
+
+	testcases := []struct {
+		method   string
+		path     string
+		status   int
+		expected string
+	}{
+		{
+			method: http.MethodGet,
+			path:   "/book/1",
+			status: http.StatusOK,
+			expected: `{"data":{"type":"book","id":"1","attributes":{"title":"some-title"},` +
+				`"relationships":{"authors":{"data":[{"type":"author","id":"2"}]},"categories":{"data":[{"type":"category","id":"3"}]}},` +
+				`"links":{"self":{"href":"/book/1"}}},` +
+				`"included":[{"type":"author","id":"2","attributes":{"name":"some-name"}},{"type":"category","id":"3","attributes":{"name":"some-category"}}]}`,
+		},
+		{
+			method: http.MethodGet,
+			path:   "/book",
+			status: http.StatusOK,
+			expected: `{"data":[` +
+				`{"type":"book","id":"1","attributes":{"title":"some-title"},"relationships":{"categories":{"data":[{"type":"category","id":"3"}]}}},` +
+				`{"type":"book","id":"2","attributes":{"title":"other-title"},"relationships":{"categories":{"data":[{"type":"category","id":"3"}]}}}],` +
+				`"included":[{"type":"category","id":"3","attributes":{"name":"some-category"}}],` +
+				`"meta":{"page":1,"size":20,"total":2,"total_pages":1},"links":{"self":{"href":"/book?page=1"}}}`,
+		},
+		{
+			method:   http.MethodDelete,
+			path:     "/book/1",
+			status:   http.StatusOK,
+			expected: `{"meta":{"message":"Delete #1 done"}}`,
+		},
+	}
+	for _, tt := range testcases {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set(echo.HeaderAccept, base.MIMEApplicationJSONAPI)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, tt.status, rec.Code, tt.path)
+		require.Equal(t, base.MIMEApplicationJSONAPI, rec.Header().Get(echo.HeaderContentType), tt.path)
+		require.JSONEq(t, tt.expected, rec.Body.String(), tt.path)
+	}
+
+	t.Run("not resource route", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/book/search", nil)
+		req.Header.Set(echo.HeaderAccept, base.MIMEApplicationJSONAPI)
+		err := base.Render(e.NewContext(req, httptest.NewRecorder()), http.StatusOK, book{ID: 1})
+		appErr, ok := base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusNotAcceptable, appErr.Status)
+	})
+}
+
+func TestBinder_JSONAPI(t *testing.T) {
+	bind := func(body string) (book, error) {
+		req := httptest.NewRequest(http.MethodPost, "/book", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, base.MIMEApplicationJSONAPI)
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		var b book
+		err := base.JSONAPI("book", bookRelationships)(func(ctx echo.Context) error {
+			return (&base.Binder{}).Bind(&b, ctx)
+		})(ctx)
+		return b, err
+	}
+
+	b, err := bind(`{"data":{"type":"book","id":"1","attributes":{"title":"some-title"},` +
+		`"relationships":{"authors":{"data":[{"type":"author","id":"2"},{"type":"author","id":"3"}]}}}}`)
+	require.NoError(t, err)
+	require.Equal(t, book{ID: 1, Title: "some-title", AuthorIDs: []int64{2, 3}}, b)
+
+	_, err = bind(`{"data":{"type":"author","attributes":{"name":"some-name"}}}`)
+	appErr, ok := base.AsError(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusConflict, appErr.Status)
+
+	_, err = bind(`{"meta":{}}`)
+	appErr, ok = base.AsError(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusBadRequest, appErr.Status)
+}
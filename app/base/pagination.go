@@ -0,0 +1,24 @@
+package base
+
+// ListResponse wrap list of data with its metadata
+type ListResponse struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta"`
+
+	// Links of the list such as next page which is only rendered in HAL and JSON:API format
+	Links Links `json:"-"`
+}
+
+// Pagination metadata of page-based listing
+type Pagination struct {
+	Page       uint64 `json:"page"`
+	Size       uint64 `json:"size"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"total_pages"`
+}
+
+// CursorPagination metadata of keyset listing; next cursor is empty at the last page
+type CursorPagination struct {
+	Size       uint64 `json:"size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,48 @@
+package base
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+// MIMEApplicationProblemJSON is media type of problem details
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem details for HTTP API (RFC 7807)
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// RequestID correlate the problem with the server log
+	RequestID string `json:"request_id,omitempty"`
+
+	// Errors of each invalid field in the request
+	Errors []validkit.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem return problem details of the error; detail of unexpected error is hidden from client
+func NewProblem(err error) *Problem {
+	if appErr, ok := AsError(err); ok {
+		return &Problem{Type: appErr.Type, Title: appErr.Title, Status: appErr.Status, Detail: appErr.Detail}
+	}
+
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		problem := &Problem{Type: ProblemBlank, Title: http.StatusText(httpErr.Code), Status: httpErr.Code}
+		if httpErr.Code < http.StatusInternalServerError {
+			problem.Detail = fmt.Sprint(httpErr.Message)
+		}
+		return problem
+	}
+
+	return &Problem{
+		Type:   ProblemBlank,
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+	}
+}
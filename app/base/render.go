@@ -0,0 +1,154 @@
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/helper/mediakit"
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+// Format of response
+const (
+	FormatJSON    = "json"
+	FormatXML     = "xml"
+	FormatMsgpack = "msgpack"
+	FormatCSV     = "csv"
+	FormatHAL     = "hal"
+	FormatJSONAPI = "jsonapi"
+)
+
+// Media type of MessagePack and CSV
+const (
+	MIMEApplicationMsgpack  = echo.MIMEApplicationMsgpack
+	MIMEApplicationXMsgpack = "application/x-msgpack"
+	MIMETextCSV             = "text/csv"
+)
+
+// xmlRoot is root element name of XML response
+const xmlRoot = "response"
+
+// formats of media type which can be responded; the first is the default
+var formats = []struct {
+	mime   string
+	format string
+}{
+	{echo.MIMEApplicationJSON, FormatJSON},
+	{echo.MIMEApplicationXML, FormatXML},
+	{echo.MIMETextXML, FormatXML},
+	{MIMEApplicationMsgpack, FormatMsgpack},
+	{MIMEApplicationXMsgpack, FormatMsgpack},
+	{MIMETextCSV, FormatCSV},
+	{MIMEApplicationHAL, FormatHAL},
+	{MIMEApplicationJSONAPI, FormatJSONAPI},
+}
+
+// Negotiate return format of the response from format query param or Accept header
+func Negotiate(ctx echo.Context) (string, error) {
+	if format := strings.ToLower(ctx.QueryParam("format")); format != "" {
+		for _, f := range formats {
+			if f.format == format {
+				return format, nil
+			}
+		}
+		return "", notAcceptable(fmt.Sprintf("unsupported format '%s'", format))
+	}
+
+	offers := make([]string, len(formats))
+	for i, f := range formats {
+		offers[i] = f.mime
+	}
+	offer := mediakit.Negotiate(ctx.Request().Header.Get(echo.HeaderAccept), offers...)
+	for _, f := range formats {
+		if f.mime == offer {
+			return f.format, nil
+		}
+	}
+	return "", notAcceptable("none of accepted media types is supported")
+}
+
+// Render respond the data in negotiated format; CSV is only supported by list and hypermedia links is only rendered in HAL and JSON:API
+func Render(ctx echo.Context, status int, data interface{}) error {
+	ctx.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	format, err := Negotiate(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatHAL:
+		hal, err := halData(data)
+		if err != nil {
+			return err
+		}
+		return renderJSON(ctx, status, MIMEApplicationHAL, hal)
+	case FormatJSONAPI:
+		doc, err := jsonapiData(ctx, data)
+		if err != nil {
+			return err
+		}
+		return renderJSON(ctx, status, MIMEApplicationJSONAPI, doc)
+	}
+
+	data = plainData(data)
+	switch format {
+	case FormatXML:
+		b, err := mediakit.MarshalXML(data, xmlRoot)
+		if err != nil {
+			return err
+		}
+		return ctx.Blob(status, echo.MIMEApplicationXMLCharsetUTF8, b)
+	case FormatMsgpack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf).UseJSONTag(true)
+		if err := enc.Encode(data); err != nil {
+			return err
+		}
+		return ctx.Blob(status, MIMEApplicationMsgpack, buf.Bytes())
+	case FormatCSV:
+		list, ok := csvList(data)
+		if !ok {
+			return notAcceptable("csv is only supported by list")
+		}
+		var buf bytes.Buffer
+		if err := mediakit.WriteCSV(&buf, list); err != nil {
+			return err
+		}
+		return ctx.Blob(status, MIMETextCSV, buf.Bytes())
+	}
+	return ctx.JSON(status, data)
+}
+
+// renderJSON respond the data as JSON in the media type
+func renderJSON(ctx echo.Context, status int, contentType string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+	return ctx.Blob(status, contentType, buf.Bytes())
+}
+
+// csvList return rows of the list response
+func csvList(data interface{}) (interface{}, bool) {
+	if res, ok := data.(ListResponse); ok {
+		data = res.Data
+	}
+	if data == nil {
+		return nil, false
+	}
+	kind := reflect.TypeOf(data).Kind()
+	return data, kind == reflect.Slice || kind == reflect.Array
+}
+
+func notAcceptable(detail string) error {
+	supported := make([]string, len(formats))
+	for i, f := range formats {
+		supported[i] = f.mime
+	}
+	return NewError(http.StatusNotAcceptable, fmt.Errorf("%s; supported media types are %s", detail, strings.Join(supported, ", ")))
+}
@@ -0,0 +1,132 @@
+package base_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+type author struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestRender(t *testing.T) {
+	list := base.ListResponse{
+		Data: []author{{ID: 1, Name: "some-name"}},
+		Meta: base.Pagination{Page: 1, Size: 20, Total: 1, TotalPages: 1},
+	}
+	testcases := []struct {
+		url         string
+		accept      string
+		data        interface{}
+		contentType string
+		expected    string
+	}{
+		{
+			url:         "/",
+			data:        author{ID: 1, Name: "some-name"},
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			expected:    `{"id":1,"name":"some-name"}` + "\n",
+		},
+		{
+			url:         "/",
+			accept:      "application/xml",
+			data:        author{ID: 1, Name: "some-name"},
+			contentType: echo.MIMEApplicationXMLCharsetUTF8,
+			expected:    `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><id>1</id><name>some-name</name></response>`,
+		},
+		{
+			url:         "/",
+			accept:      "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			data:        author{ID: 1, Name: "some-name"},
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			expected:    `{"id":1,"name":"some-name"}` + "\n",
+		},
+		{
+			url:         "/?format=csv",
+			accept:      "application/json",
+			data:        list,
+			contentType: base.MIMETextCSV,
+			expected:    "id,name\n1,some-name\n",
+		},
+	}
+
+	for _, tt := range testcases {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		req.Header.Set(echo.HeaderAccept, tt.accept)
+		rec := httptest.NewRecorder()
+		require.NoError(t, base.Render(echo.New().NewContext(req, rec), http.StatusOK, tt.data))
+		require.Equal(t, tt.contentType, rec.Header().Get(echo.HeaderContentType), tt.url)
+		require.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary), tt.url)
+		require.Equal(t, tt.expected, rec.Body.String(), tt.url)
+	}
+
+	t.Run("msgpack", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAccept, "application/x-msgpack")
+		rec := httptest.NewRecorder()
+		require.NoError(t, base.Render(echo.New().NewContext(req, rec), http.StatusOK, author{ID: 1, Name: "some-name"}))
+		require.Equal(t, base.MIMEApplicationMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+		var decoded map[string]interface{}
+		require.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &decoded))
+		require.Equal(t, "some-name", decoded["name"])
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		for _, tt := range []struct {
+			url    string
+			accept string
+			data   interface{}
+		}{
+			{url: "/", accept: "text/html"},
+			{url: "/?format=yaml"},
+			{url: "/", accept: "text/csv", data: author{ID: 1}},
+		} {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req.Header.Set(echo.HeaderAccept, tt.accept)
+			err := base.Render(echo.New().NewContext(req, httptest.NewRecorder()), http.StatusOK, tt.data)
+			appErr, ok := base.AsError(err)
+			require.True(t, ok, tt.url)
+			require.Equal(t, http.StatusNotAcceptable, appErr.Status, tt.url)
+		}
+	})
+}
+
+func TestBinder(t *testing.T) {
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"id": 1, "name": "some-name"})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		contentType string
+		body        []byte
+	}{
+		{echo.MIMEApplicationJSON, []byte(`{"id":1,"name":"some-name"}`)},
+		{echo.MIMEApplicationXML, []byte(`<author><id>1</id><name>some-name</name></author>`)},
+		{base.MIMEApplicationMsgpack, msgpackBody},
+	}
+
+	for _, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, tt.contentType)
+		var a author
+		require.NoError(t, (&base.Binder{}).Bind(&a, echo.New().NewContext(req, httptest.NewRecorder())), tt.contentType)
+		require.Equal(t, author{ID: 1, Name: "some-name"}, a, tt.contentType)
+	}
+
+	t.Run("unsupported media type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("id,name"))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		var a author
+		err := (&base.Binder{}).Bind(&a, echo.New().NewContext(req, httptest.NewRecorder()))
+		require.Equal(t, echo.ErrUnsupportedMediaType, err)
+	})
+}
@@ -1,20 +1,49 @@
 package controller
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/labstack/echo"
+	authormodels "github.com/typical-go/typical-rest-server/app/author/models"
 	"github.com/typical-go/typical-rest-server/app/base"
 	"github.com/typical-go/typical-rest-server/app/book/models"
 	"github.com/typical-go/typical-rest-server/app/book/service"
+	categorymodels "github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/app/helper/etagkit"
 	"github.com/typical-go/typical-rest-server/app/helper/strkit"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
 )
 
 // BookController handle input related to Book
 type BookController interface {
 	base.BaseCRUDController
 	//Put new route belows
+	Search(c echo.Context) error
+	Patch(c echo.Context) error
+	Trash(c echo.Context) error
+	Restore(c echo.Context) error
+	Bulk(c echo.Context) error
+	Export(c echo.Context) error
+	Import(c echo.Context) error
+	GetByISBN(c echo.Context) error
+	AttachTags(c echo.Context) error
+	DetachTag(c echo.Context) error
+	UploadCover(c echo.Context) error
+	GetCover(c echo.Context) error
+	History(c echo.Context) error
+	Upsert(c echo.Context) error
+	Revert(c echo.Context) error
 }
 
 //InitBookController struct
@@ -24,14 +53,16 @@ type InitBookController struct {
 
 //InitBookServiceInterface struct
 type InitBookServiceInterface struct {
-	Book service.BookService
+	Book  service.BookService
+	Cover service.CoverService
 }
 
 // NewBookController return new instance of book controller
-func NewBookController(bookService service.BookService) BookController {
+func NewBookController(bookService service.BookService, coverService service.CoverService) BookController {
 	return &InitBookController{
 		Service: &InitBookServiceInterface{
-			Book: bookService,
+			Book:  bookService,
+			Cover: coverService,
 		},
 	}
 }
@@ -40,29 +71,177 @@ func NewBookController(bookService service.BookService) BookController {
 func (c *InitBookController) Get(ctx echo.Context) error {
 	id, err := strkit.ToInt64(ctx.Param("id"))
 	if err != nil {
-		return invalidID(ctx, err)
+		return invalidID(err)
 	}
 
-	book, err := c.Service.Book.GetBook(id)
+	var criteria models.BookCriteria
+	if err = bookFieldset(ctx, &criteria); err != nil {
+		return invalidMessage(err)
+	}
+
+	book, err := c.Service.Book.GetBook(ctx.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
 	if book == nil {
-		return ctx.JSON(http.StatusNotFound, map[string]string{"message": fmt.Sprintf("book #%d not found", id)})
+		return notFound("book #%d not found", id)
+	}
+
+	ctx.Response().Header().Set(headerETag, book.ETag())
+	if header := ctx.Request().Header.Get(headerIfNoneMatch); header != "" && etagkit.Match(header, book.ETag()) {
+		ctx.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+		return ctx.NoContent(http.StatusNotModified)
 	}
 
-	return ctx.JSON(http.StatusOK, book)
+	return base.Render(ctx, http.StatusOK, bookResource(ctx, book, sparseBook(book, criteria)))
+}
+
+//GetByISBN func
+func (c *InitBookController) GetByISBN(ctx echo.Context) error {
+	isbn := ctx.Param("isbn")
+	if !models.ValidISBN(isbn) {
+		return invalidMessage(fmt.Errorf("invalid isbn '%s'", isbn))
+	}
+
+	var criteria models.BookCriteria
+	if err := bookFieldset(ctx, &criteria); err != nil {
+		return invalidMessage(err)
+	}
+
+	book, err := c.Service.Book.GetBookByISBN(ctx.Request().Context(), isbn)
+	if err != nil {
+		return err
+	}
+
+	if book == nil {
+		return notFound("book with isbn %s not found", isbn)
+	}
+
+	ctx.Response().Header().Set(headerETag, book.ETag())
+	return base.Render(ctx, http.StatusOK, bookResource(ctx, book, sparseBook(book, criteria)))
 }
 
 //List func
 func (c *InitBookController) List(ctx echo.Context) error {
-	books, err := c.Service.Book.ListBook()
+	criteria, err := bookCriteria(ctx)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	return c.list(ctx, base.RouteName(bookEntity, base.ActionList), criteria)
+}
+
+// list respond the books in page or by cursor; the route is reversed into pagination link
+func (c *InitBookController) list(ctx echo.Context, route string, criteria models.BookCriteria) error {
+	if criteria.Keyset {
+		return c.listByCursor(ctx, route, criteria)
+	}
+
+	books, total, err := c.Service.Book.ListBook(ctx.Request().Context(), criteria)
+	if errors.Is(err, models.ErrInvalidSort) {
+		return invalidMessage(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	meta := base.Pagination{
+		Page:       criteria.Page,
+		Size:       criteria.Size,
+		Total:      total,
+		TotalPages: criteria.TotalPages(total),
+	}
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data:  bookResources(ctx, books, criteria),
+		Meta:  meta,
+		Links: pageLinks(ctx, route, meta),
+	})
+}
+
+func (c *InitBookController) listByCursor(ctx echo.Context, route string, criteria models.BookCriteria) error {
+	books, next, err := c.Service.Book.ListBookByCursor(ctx.Request().Context(), criteria)
+	if errors.Is(err, models.ErrInvalidSort) || errors.Is(err, models.ErrInvalidCursor) {
+		return invalidMessage(err)
+	}
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, books)
+	meta := base.CursorPagination{Size: criteria.Size}
+	if next != nil {
+		meta.NextCursor = next.Encode()
+	}
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data:  bookResources(ctx, books, criteria),
+		Meta:  meta,
+		Links: cursorLinks(ctx, route, meta),
+	})
+}
+
+//Search func
+func (c *InitBookController) Search(ctx echo.Context) error {
+	query := strings.TrimSpace(ctx.QueryParam("q"))
+	if query == "" {
+		return invalidMessage(errors.New("q is required"))
+	}
+
+	criteria, err := bookCriteria(ctx)
+	if err != nil {
+		return invalidMessage(err)
+	}
+	if criteria.Keyset {
+		return invalidMessage(errors.New("search does not support cursor"))
+	}
+
+	results, total, err := c.Service.Book.SearchBook(ctx.Request().Context(), query, criteria)
+	if err != nil {
+		return err
+	}
+
+	meta := base.Pagination{
+		Page:       criteria.Page,
+		Size:       criteria.Size,
+		Total:      total,
+		TotalPages: criteria.TotalPages(total),
+	}
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data:  searchResources(ctx, results, criteria),
+		Meta:  meta,
+		Links: pageLinks(ctx, RouteBookSearch, meta),
+	})
+}
+
+//Trash func
+func (c *InitBookController) Trash(ctx echo.Context) error {
+	criteria, err := bookCriteria(ctx)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	criteria.Trashed = true
+	return c.list(ctx, RouteBookTrash, criteria)
+}
+
+//Restore func
+func (c *InitBookController) Restore(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	err = c.Service.Book.RestoreBook(ctx.Request().Context(), id)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found in trash", id)
+	}
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		return conflict(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": fmt.Sprintf("Restore #%d done", id)})
 }
 
 //Create func
@@ -75,10 +254,16 @@ func (c *InitBookController) Create(ctx echo.Context) (err error) {
 
 	err = book.Validate()
 	if err != nil {
-		return invalidMessage(ctx, err)
+		return invalidMessage(err)
 	}
 
-	result, err := c.Service.Book.CreateBook(book)
+	result, err := c.Service.Book.CreateBook(ctx.Request().Context(), book)
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		return conflict(err)
+	}
+	if errors.Is(err, authormodels.ErrAuthorNotFound) || errors.Is(err, categorymodels.ErrCategoryNotFound) {
+		return invalidMessage(err)
+	}
 	if err != nil {
 		return err
 	}
@@ -87,6 +272,196 @@ func (c *InitBookController) Create(ctx echo.Context) (err error) {
 
 }
 
+//Bulk func
+func (c *InitBookController) Bulk(ctx echo.Context) (err error) {
+	var request models.BulkRequest
+	err = ctx.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	results, ok := c.Service.Book.BulkBook(ctx.Request().Context(), request)
+	for _, result := range results {
+		if result.Err != nil {
+			result.Detail, result.Errors = describeError(ctx, result.Err)
+		}
+	}
+
+	status := http.StatusOK
+	switch {
+	case !ok && request.Mode == models.BulkAtomic:
+		status = http.StatusUnprocessableEntity
+	case !ok:
+		status = http.StatusMultiStatus
+	}
+
+	return base.Render(ctx, status, map[string]interface{}{
+		"mode":    request.Mode,
+		"success": ok,
+		"data":    results,
+	})
+}
+
+//Export func
+func (c *InitBookController) Export(ctx echo.Context) (err error) {
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = formatCSV
+	}
+
+	criteria, err := bookCriteria(ctx)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	res := ctx.Response()
+	writer, err := newBookWriter(format, res)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	contentType := mimeCSV
+	if format == formatJSONL {
+		contentType = mimeJSONL
+	}
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="books.%s"`, format))
+
+	count := 0
+	err = c.Service.Book.ExportBook(ctx.Request().Context(), criteria, func(book *models.Book) error {
+		if err := writer.Write(book); err != nil {
+			return err
+		}
+		count++
+		if count%importBatchSize == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			res.Flush()
+		}
+		return nil
+	})
+
+	// NOTE: error can only be responded when nothing is streamed yet
+	if err != nil && !res.Committed {
+		res.Header().Del(echo.HeaderContentDisposition)
+		if errors.Is(err, models.ErrInvalidSort) {
+			return invalidMessage(err)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+//Import func
+func (c *InitBookController) Import(ctx echo.Context) (err error) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+	if format == "ndjson" {
+		format = formatJSONL
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	reader, err := newBookReader(format, src)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	type rowError struct {
+		Row    int                   `json:"row"`
+		Detail string                `json:"detail"`
+		Errors []validkit.FieldError `json:"errors,omitempty"`
+	}
+	newRowError := func(row int, err error) rowError {
+		detail, fieldErrs := describeError(ctx, err)
+		return rowError{Row: row, Detail: detail, Errors: fieldErrs}
+	}
+
+	var (
+		imported  int
+		rowErrors []rowError
+		batch     []models.Book
+		batchRows []int
+	)
+
+	insertBatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		_, errs := c.Service.Book.ImportBook(ctx.Request().Context(), batch)
+		for i, err := range errs {
+			if err != nil {
+				rowErrors = append(rowErrors, newRowError(batchRows[i], err))
+				continue
+			}
+			imported++
+		}
+		batch, batchRows = batch[:0], batchRows[:0]
+	}
+
+	for {
+		row, book, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &parseErr) && !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+				return invalidMessage(err)
+			}
+			rowErrors = append(rowErrors, newRowError(row, err))
+			continue
+		}
+
+		if err = book.Validate(); err != nil {
+			rowErrors = append(rowErrors, newRowError(row, err))
+			continue
+		}
+
+		batch = append(batch, book)
+		batchRows = append(batchRows, row)
+		if len(batch) >= importBatchSize {
+			insertBatch()
+		}
+	}
+	insertBatch()
+
+	status := http.StatusOK
+	if len(rowErrors) > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	// NOTE: format query param is format of the imported file so the result is always JSON
+	return ctx.JSON(status, map[string]interface{}{
+		"imported": imported,
+		"failed":   len(rowErrors),
+		"errors":   rowErrors,
+	})
+}
+
 //Update func
 func (c *InitBookController) Update(ctx echo.Context) (err error) {
 	var book models.Book
@@ -97,33 +472,402 @@ func (c *InitBookController) Update(ctx echo.Context) (err error) {
 	}
 
 	if book.ID <= 0 {
-		return invalidID(ctx, err)
+		return invalidID(err)
+	}
+
+	err = book.Validate()
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	version, matched, err := c.expectedVersion(ctx, book.ID)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", book.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return preconditionFailed()
+	}
+	book.Version = version
+
+	err = c.Service.Book.UpdateBook(ctx.Request().Context(), book)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", book.ID)
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		return preconditionFailed()
+	}
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		return conflict(err)
+	}
+	if errors.Is(err, authormodels.ErrAuthorNotFound) || errors.Is(err, categorymodels.ErrCategoryNotFound) {
+		return invalidMessage(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the updated version is read back so the next conditional write need no extra request
+	updated, err := c.Service.Book.GetBook(ctx.Request().Context(), book.ID)
+	if err != nil {
+		return err
+	}
+	if updated != nil {
+		ctx.Response().Header().Set(headerETag, updated.ETag())
+	}
+
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": "Update success"})
+}
+
+//Upsert func
+func (c *InitBookController) Upsert(ctx echo.Context) (err error) {
+	key, err := models.ParseNaturalKey(ctx.QueryParam("key"))
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	var book models.Book
+	err = ctx.Bind(&book)
+	if err != nil {
+		return err
 	}
 
 	err = book.Validate()
 	if err != nil {
-		return invalidMessage(ctx, err)
+		return invalidMessage(err)
+	}
+
+	id, created, err := c.Service.Book.UpsertBook(ctx.Request().Context(), key, book)
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		return conflict(err)
+	}
+	if errors.Is(err, models.ErrMissingNaturalKey) ||
+		errors.Is(err, authormodels.ErrAuthorNotFound) || errors.Is(err, categorymodels.ErrCategoryNotFound) {
+		return invalidMessage(err)
+	}
+	if err != nil {
+		return err
 	}
 
-	err = c.Service.Book.UpdateBook(book)
+	if created {
+		return insertSuccess(ctx, id)
+	}
+	return base.Render(ctx, http.StatusOK, map[string]interface{}{"message": fmt.Sprintf("Success update record #%d", id), "data": id})
+}
+
+//Patch func
+func (c *InitBookController) Patch(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	var criteria models.BookCriteria
+	if err = bookFieldset(ctx, &criteria); err != nil {
+		return invalidMessage(err)
+	}
+
+	doc, err := ioutil.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	book, err := c.Service.Book.GetBook(ctx.Request().Context(), id)
 	if err != nil {
 		return err
 	}
+	if book == nil {
+		return notFound("book #%d not found", id)
+	}
+	if header := ctx.Request().Header.Get(headerIfMatch); header != "" && !etagkit.Match(header, book.ETag()) {
+		return preconditionFailed()
+	}
 
-	return ctx.JSON(http.StatusOK, map[string]string{"message": "Update success"})
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	patched, err := book.Patch(mediaType, doc)
+	if errors.Is(err, models.ErrUnsupportedPatch) {
+		return base.NewError(http.StatusUnsupportedMediaType, err)
+	}
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	err = patched.Validate()
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	// NOTE: patch is always conditional to the version fetched above so concurrent change is not overwritten
+	columns := book.ChangedColumns(patched)
+	err = c.Service.Book.PatchBook(ctx.Request().Context(), *patched, columns)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", id)
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		return preconditionFailed()
+	}
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		return conflict(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the book is read again so its linked authors and version is the stored one
+	book, err = c.Service.Book.GetBook(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	if book == nil {
+		return notFound("book #%d not found", id)
+	}
+
+	ctx.Response().Header().Set(headerETag, book.ETag())
+	return base.Render(ctx, http.StatusOK, bookResource(ctx, book, sparseBook(book, criteria)))
 }
 
 //Delete func
 func (c *InitBookController) Delete(ctx echo.Context) error {
 	id, err := strkit.ToInt64(ctx.Param("id"))
 	if err != nil {
-		return invalidID(ctx, err)
+		return invalidID(err)
+	}
+
+	version, matched, err := c.expectedVersion(ctx, id)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", id)
+	}
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return preconditionFailed()
+	}
+
+	err = c.Service.Book.DeleteBook(ctx.Request().Context(), id, version)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", id)
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		return preconditionFailed()
+	}
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": fmt.Sprintf("Delete #%d done", id)})
+}
+
+//AttachTags func
+func (c *InitBookController) AttachTags(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	var request struct {
+		Tags []string `json:"tags" validate:"required,min=1"`
+	}
+	if err = ctx.Bind(&request); err != nil {
+		return err
+	}
+	if err = validkit.Struct(request); err != nil {
+		return invalidMessage(err)
+	}
+
+	tags, err := models.NormalizeTags(request.Tags)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	return c.changeTags(ctx, id, tags, c.Service.Book.AttachBookTags)
+}
+
+//DetachTag func
+func (c *InitBookController) DetachTag(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	tags, err := models.NormalizeTags([]string{ctx.Param("tag")})
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	return c.changeTags(ctx, id, tags, c.Service.Book.DetachBookTags)
+}
+
+func (c *InitBookController) changeTags(ctx echo.Context, id int64, tags []string, change func(context.Context, int64, []string) ([]string, error)) error {
+	book, err := c.Service.Book.GetBook(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	if book == nil {
+		return notFound("book #%d not found", id)
+	}
+
+	tags, err = change(ctx.Request().Context(), id, tags)
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, map[string]interface{}{"tags": tags})
+}
+
+//UploadCover func
+func (c *InitBookController) UploadCover(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
 	}
 
-	err = c.Service.Book.DeleteBook(id)
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	err = c.Service.Cover.UploadCover(ctx.Request().Context(), id, src)
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", id)
+	}
+	if errors.Is(err, models.ErrCoverTooLarge) {
+		return base.NewError(http.StatusRequestEntityTooLarge, err)
+	}
+	if errors.Is(err, models.ErrUnsupportedCover) {
+		return base.NewError(http.StatusUnsupportedMediaType, err)
+	}
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]string{"message": fmt.Sprintf("Delete #%d done", id)})
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": fmt.Sprintf("Upload cover of #%d done", id)})
+}
+
+//GetCover func
+func (c *InitBookController) GetCover(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	object, err := c.Service.Cover.GetCover(ctx.Request().Context(), id, ctx.QueryParam("size"))
+	if errors.Is(err, models.ErrInvalidCoverSize) {
+		return invalidMessage(err)
+	}
+	if errors.Is(err, models.ErrBookNotFound) || errors.Is(err, models.ErrCoverNotFound) {
+		return notFound("cover of book #%d not found", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	// NOTE: ServeContent answer conditional request (If-None-Match, If-Modified-Since) and sniff the content type
+	header := ctx.Response().Header()
+	header.Set(headerETag, fmt.Sprintf(`"%s"`, object.ETag))
+	header.Set(headerCacheControl, coverCacheControl)
+	http.ServeContent(ctx.Response(), ctx.Request(), "", object.ModTime, bytes.NewReader(object.Data))
+	return nil
+}
+
+//History func
+func (c *InitBookController) History(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	criteria, err := bookCriteria(ctx)
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	audits, total, err := c.Service.Book.BookHistory(ctx.Request().Context(), id, criteria)
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data: audits,
+		Meta: base.Pagination{
+			Page:       criteria.Page,
+			Size:       criteria.Size,
+			Total:      total,
+			TotalPages: criteria.TotalPages(total),
+		},
+	})
+}
+
+//Revert func
+func (c *InitBookController) Revert(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	revision, err := strkit.ToInt64(ctx.Param("revision"))
+	if err != nil {
+		return invalidMessage(fmt.Errorf("invalid revision '%s'", ctx.Param("revision")))
+	}
+
+	book, err := c.Service.Book.GetBook(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	if book == nil {
+		return notFound("book #%d not found", id)
+	}
+	if header := ctx.Request().Header.Get(headerIfMatch); header != "" && !etagkit.Match(header, book.ETag()) {
+		return preconditionFailed()
+	}
+
+	// NOTE: revert is always conditional to the version fetched above like patch
+	reverted, err := c.Service.Book.RevertBook(ctx.Request().Context(), id, revision, book.Version)
+	if errors.Is(err, models.ErrRevisionNotFound) {
+		return notFound("revision #%d of book #%d not found", revision, id)
+	}
+	if errors.Is(err, models.ErrBookNotFound) {
+		return notFound("book #%d not found", id)
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		return preconditionFailed()
+	}
+	if errors.Is(err, models.ErrDuplicateISBN) {
+		return conflict(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Response().Header().Set(headerETag, reverted.ETag())
+	return base.Render(ctx, http.StatusOK, reverted)
+}
+
+// expectedVersion return current version of the book when it match with If-Match header; version 0 means no precondition
+func (c *InitBookController) expectedVersion(ctx echo.Context, id int64) (version int64, matched bool, err error) {
+	header := ctx.Request().Header.Get(headerIfMatch)
+	if header == "" {
+		return 0, true, nil
+	}
+
+	book, err := c.Service.Book.GetBook(ctx.Request().Context(), id)
+	if err != nil {
+		return 0, false, err
+	}
+	if book == nil {
+		return 0, false, models.ErrBookNotFound
+	}
+	if !etagkit.Match(header, book.ETag()) {
+		return 0, false, nil
+	}
+
+	return book.Version, true, nil
 }
@@ -5,21 +5,28 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/bxcodec/faker"
 	"github.com/labstack/echo"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/base"
 	"github.com/typical-go/typical-rest-server/app/book/controller"
 	"github.com/typical-go/typical-rest-server/app/book/mocks"
 	"github.com/typical-go/typical-rest-server/app/book/models"
 	"github.com/typical-go/typical-rest-server/app/book/repository"
 	"github.com/typical-go/typical-rest-server/app/book/service"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
 )
 
 var BookID int64
@@ -30,10 +37,10 @@ func TestBookControllerGet(t *testing.T) {
 
 	id := int(1)
 
-	mockService.On("GetBook", int64(id)).Return(mockBook, nil)
+	mockService.On("GetBook", mock.Anything, int64(id)).Return(mockBook, nil)
 	tt := new(testing.T)
 	assert.False(t, mockService.AssertExpectations(tt))
-	mockService.GetBook(int64(id))
+	mockService.GetBook(context.TODO(), int64(id))
 	assert.True(t, mockService.AssertExpectations(tt))
 
 	e := echo.New()
@@ -48,7 +55,7 @@ func TestBookControllerGet(t *testing.T) {
 
 	conn := mocks.GetMockConnection()
 	bookRepository := repository.NewBookRepository(conn)
-	bookService := service.NewBookService(bookRepository)
+	bookService := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 	initBookServiceInterface := &controller.InitBookServiceInterface{
 		Book: bookService,
 	}
@@ -72,10 +79,10 @@ func TestBookControllerList(t *testing.T) {
 		&models.Book{ID: 4, Title: "test4", Author: "test4"},
 	}
 
-	mockService.On("ListBook", mock.Anything).Return(mockListBook, nil)
+	mockService.On("ListBook", mock.Anything, mock.Anything).Return(mockListBook, int64(len(mockListBook)), nil)
 	tt := new(testing.T)
 	assert.False(t, mockService.AssertExpectations(tt))
-	mockService.ListBook()
+	mockService.ListBook(context.TODO(), models.BookCriteria{})
 	assert.True(t, mockService.AssertExpectations(tt))
 
 	e := echo.New()
@@ -87,7 +94,7 @@ func TestBookControllerList(t *testing.T) {
 
 	conn := mocks.GetMockConnection()
 	bookRepository := repository.NewBookRepository(conn)
-	bookService := service.NewBookService(bookRepository)
+	bookService := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 	initBookServiceInterface := &controller.InitBookServiceInterface{
 		Book: bookService,
 	}
@@ -98,7 +105,10 @@ func TestBookControllerList(t *testing.T) {
 	err = initBookController.List(c)
 	require.NoError(t, err)
 
-	expect, _ := json.Marshal(&mockListBook)
+	expect, _ := json.Marshal(base.ListResponse{
+		Data: mockListBook,
+		Meta: base.Pagination{Page: 1, Size: models.DefaultPageSize, Total: 3, TotalPages: 1},
+	})
 	assert.Equal(t, string(expect)+"\n", rec.Body.String())
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
@@ -108,6 +118,12 @@ func TestBookControllerCreate(t *testing.T) {
 	var mockBook models.Book
 	err := faker.FakeData(&mockBook)
 	assert.NoError(t, err)
+	mockBook.ISBN = "9780306406157"
+	mockBook.PublishedYear = 1999
+	mockBook.Language = "en"
+	mockBook.PageCount = 300
+	mockBook.AuthorIDs, mockBook.Authors = nil, nil
+	mockBook.CategoryIDs, mockBook.Categories, mockBook.Tags = nil, nil, nil
 
 	mockService.On("CreateBook", mock.Anything, mock.AnythingOfType("*models.Book")).Return(mockBook.ID, nil)
 	tt := new(testing.T)
@@ -129,7 +145,7 @@ func TestBookControllerCreate(t *testing.T) {
 
 	conn := mocks.GetMockConnection()
 	bookRepository := repository.NewBookRepository(conn)
-	bookService := service.NewBookService(bookRepository)
+	bookService := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 	initBookServiceInterface := &controller.InitBookServiceInterface{
 		Book: bookService,
 	}
@@ -176,7 +192,7 @@ func TestBookControllerUpdate(t *testing.T) {
 
 	conn := mocks.GetMockConnection()
 	bookRepository := repository.NewBookRepository(conn)
-	bookService := service.NewBookService(bookRepository)
+	bookService := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 	initBookServiceInterface := &controller.InitBookServiceInterface{
 		Book: bookService,
 	}
@@ -195,10 +211,10 @@ func TestBookControllerDelete(t *testing.T) {
 
 	id := int(BookID)
 
-	mockService.On("DeleteBook", mock.Anything, int64(id)).Return(nil)
+	mockService.On("DeleteBook", mock.Anything, int64(id), int64(0)).Return(nil)
 	tt := new(testing.T)
 	assert.False(t, mockService.AssertExpectations(tt))
-	mockService.DeleteBook(context.TODO(), int64(id))
+	mockService.DeleteBook(context.TODO(), int64(id), 0)
 	assert.True(t, mockService.AssertExpectations(tt))
 
 	e := echo.New()
@@ -213,7 +229,7 @@ func TestBookControllerDelete(t *testing.T) {
 
 	conn := mocks.GetMockConnection()
 	bookRepository := repository.NewBookRepository(conn)
-	bookService := service.NewBookService(bookRepository)
+	bookService := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 	initBookServiceInterface := &controller.InitBookServiceInterface{
 		Book: bookService,
 	}
@@ -226,3 +242,122 @@ func TestBookControllerDelete(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+func TestBookControllerPatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	findSQL := regexp.QuoteMeta(`FROM books WHERE deleted_at IS NULL AND id = $1`)
+	bookAuthorRows := func(authorID int64, name string) *sqlmock.Rows {
+		return sqlmock.NewRows(append([]string{"book_id"}, authorrepo.AuthorColumns...)).AddRow(7, authorID, name, time.Now(), time.Now())
+	}
+	expectGetBook := func(author string, authorID, version int64) {
+		mock.ExpectQuery(findSQL).WithArgs(7).WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+			AddRow(7, "some-title", author, "", 0, "", "", 0, version, time.Now(), time.Now(), nil))
+		mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows(authorID, author))
+		mock.ExpectQuery(`FROM book_categories`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id"}))
+		mock.ExpectQuery(`FROM book_tags`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}))
+	}
+
+	expectGetBook("old-author", 20, 3)
+	mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows(20, "old-author"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO authors (name) VALUES ($1) ON CONFLICT (name) DO UPDATE`)).WithArgs("new-author").
+		WillReturnRows(sqlmock.NewRows(authorrepo.AuthorColumns).AddRow(21, "new-author", time.Now(), time.Now()))
+	mock.ExpectExec(`UPDATE books SET author = \$2`).
+		WithArgs(7, "new-author", sqlmock.AnyArg(), 7, 3, models.AuditPatch, "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO book_authors (book_id,author_id,position) VALUES ($1,$2,$3)`)).
+		WithArgs(7, 21, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectGetBook("new-author", 21, 4)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/book/7?fields=author&expand=authors", strings.NewReader(`{"author":"new-author"}`))
+	req.Header.Set(echo.HeaderContentType, models.MIMEMergePatch)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("7")
+
+	bookController := controller.NewBookController(service.NewBookService(repository.NewBookRepository(db),
+		authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db)), nil)
+	require.NoError(t, bookController.Patch(ctx))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `W/"4"`, rec.Header().Get("ETag"))
+
+	var body struct {
+		Title   string `json:"title"`
+		Author  string `json:"author"`
+		Authors []struct {
+			ID int64 `json:"id"`
+		} `json:"authors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Empty(t, body.Title)
+	require.Equal(t, "new-author", body.Author)
+	require.Len(t, body.Authors, 1)
+	require.Equal(t, int64(21), body.Authors[0].ID)
+}
+
+func TestBookControllerPut(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	bookAuthorRows := sqlmock.NewRows(append([]string{"book_id"}, authorrepo.AuthorColumns...)).AddRow(7, 20, "some-author", time.Now(), time.Now())
+	mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE books SET title = \$2`).
+		WithArgs(7, "some-title", "some-author", "", 0, "", "", 0, sqlmock.AnyArg(), 7, models.AuditUpdate, "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO book_authors`).WithArgs(7, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM books WHERE deleted_at IS NULL AND id = $1`)).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+			AddRow(7, "some-title", "some-author", "", 0, "", "", 0, 5, time.Now(), time.Now(), nil))
+	mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id"}))
+	mock.ExpectQuery(`FROM book_categories`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id"}))
+	mock.ExpectQuery(`FROM book_tags`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/book", strings.NewReader(`{"id":7,"title":"some-title","author":"some-author"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	bookController := controller.NewBookController(service.NewBookService(repository.NewBookRepository(db),
+		authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db)), nil)
+	require.NoError(t, bookController.Update(e.NewContext(req, rec)))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `W/"5"`, rec.Header().Get("ETag"))
+	require.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+}
+
+func TestBookControllerAttachTags(t *testing.T) {
+	bookController := controller.NewBookController(nil, nil)
+	for body, rule := range map[string]string{`{}`: "required", `{"tags":[]}`: "min"} {
+		req := httptest.NewRequest(http.MethodPost, "/book/7/tags", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("7")
+
+		err := bookController.AttachTags(ctx)
+		appErr, ok := base.AsError(err)
+		require.True(t, ok, body)
+		require.Equal(t, http.StatusBadRequest, appErr.Status)
+		require.NotEmpty(t, appErr.Detail)
+
+		fieldErrs := validkit.FieldErrors(err, validkit.Translator(""))
+		require.Len(t, fieldErrs, 1)
+		require.Equal(t, "tags", fieldErrs[0].Field)
+		require.Equal(t, rule, fieldErrs[0].Rule)
+	}
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+// bookCriteria parse query parameter of book listing
+func bookCriteria(ctx echo.Context) (criteria models.BookCriteria, err error) {
+	criteria = models.BookCriteria{
+		Title:      ctx.QueryParam("title"),
+		TitleLike:  ctx.QueryParam("title_like"),
+		Author:     ctx.QueryParam("author"),
+		AuthorLike: ctx.QueryParam("author_like"),
+		Sort:       ctx.QueryParam("sort"),
+		Page:       1,
+		Size:       models.DefaultPageSize,
+	}
+
+	if criteria.Page, err = uintQueryParam(ctx, "page", criteria.Page); err != nil {
+		return
+	}
+	if criteria.Size, err = uintQueryParam(ctx, "size", criteria.Size); err != nil {
+		return
+	}
+
+	var authorID uint64
+	if authorID, err = uintQueryParam(ctx, "author_id", 0); err != nil {
+		return
+	}
+	criteria.AuthorID = int64(authorID)
+
+	var categoryID uint64
+	if categoryID, err = uintQueryParam(ctx, "category", 0); err != nil {
+		return
+	}
+	criteria.CategoryID = int64(categoryID)
+
+	if tags := ctx.QueryParams()["tag"]; len(tags) > 0 {
+		if criteria.Tags, err = models.NormalizeTags(tags); err != nil {
+			return
+		}
+	}
+
+	// keyset pagination when cursor is requested; empty cursor start from the first book
+	if cursor, ok := ctx.QueryParams()["cursor"]; ok {
+		criteria.Keyset = true
+		if len(cursor) > 0 && cursor[0] != "" {
+			if criteria.After, err = models.DecodeCursor(cursor[0]); err != nil {
+				return
+			}
+			if criteria.Sort == "" {
+				criteria.Sort = criteria.After.Sort()
+			}
+		}
+	}
+
+	if err = bookFieldset(ctx, &criteria); err != nil {
+		return
+	}
+
+	if criteria.Page < 1 {
+		return criteria, fmt.Errorf("page must be greater than 0")
+	}
+	if criteria.Size < 1 || criteria.Size > models.MaxPageSize {
+		return criteria, fmt.Errorf("size must be between 1 and %d", models.MaxPageSize)
+	}
+
+	return
+}
+
+func uintQueryParam(ctx echo.Context, name string, defaultValue uint64) (uint64, error) {
+	s := ctx.QueryParam(name)
+	if s == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/helper/mediakit"
+)
+
+// bookFieldset parse fields and expand query param into the criteria; relation is not expanded by default when fields is selected
+func bookFieldset(ctx echo.Context, criteria *models.BookCriteria) (err error) {
+	if criteria.Fields, err = models.ParseFields(ctx.QueryParam("fields")); err != nil {
+		return
+	}
+	if expand, ok := ctx.QueryParams()["expand"]; ok {
+		criteria.Expand, err = models.ParseExpand(strings.Join(expand, ","))
+	} else if len(criteria.Fields) > 0 {
+		criteria.Expand = []string{}
+	}
+	return
+}
+
+// sparseBook return the book with only the selected fields and expanded relations; other value such as rank of search result is kept
+func sparseBook(book interface{}, criteria models.BookCriteria) interface{} {
+	if len(criteria.Fields) == 0 && criteria.Expand == nil {
+		return book
+	}
+
+	fields := mediakit.JSONFields(book)
+	for name := range fields {
+		if models.IsBookField(name) && !criteria.Selects(name) || models.IsBookRelation(name) && !criteria.Expands(name) {
+			delete(fields, name)
+		}
+	}
+	return fields
+}
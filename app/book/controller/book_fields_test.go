@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+func TestBookFieldset(t *testing.T) {
+	testcases := []struct {
+		url    string
+		fields []string
+		expand []string
+		err    error
+	}{
+		{url: "/book"},
+		{url: "/book?fields=id,title", fields: []string{"id", "title"}, expand: []string{}},
+		{url: "/book?fields=title&expand=tags", fields: []string{"title"}, expand: []string{"tags"}},
+		{url: "/book?expand=authors&expand=tags", fields: []string{}, expand: []string{"authors", "tags"}},
+		{url: "/book?fields=password", err: models.ErrUnknownField},
+		{url: "/book?expand=reviews", fields: []string{}, err: models.ErrUnknownRelation},
+	}
+
+	for _, tt := range testcases {
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, tt.url, nil), httptest.NewRecorder())
+		var criteria models.BookCriteria
+		err := bookFieldset(ctx, &criteria)
+		if tt.err != nil {
+			require.True(t, errors.Is(err, tt.err), tt.url)
+			continue
+		}
+		require.NoError(t, err, tt.url)
+		if tt.fields != nil {
+			require.Equal(t, tt.fields, criteria.Fields, tt.url)
+		} else {
+			require.Empty(t, criteria.Fields, tt.url)
+		}
+		require.Equal(t, tt.expand, criteria.Expand, tt.url)
+	}
+}
+
+func TestSparseBook(t *testing.T) {
+	book := &models.Book{ID: 1, Title: "some-title", Author: "some-author", Tags: []string{"go"}}
+
+	require.Equal(t, book, sparseBook(book, models.BookCriteria{}))
+
+	require.Equal(t, map[string]interface{}{"id": int64(1), "title": "some-title"},
+		sparseBook(book, models.BookCriteria{Fields: []string{"id", "title"}, Expand: []string{}}))
+
+	require.Equal(t, map[string]interface{}{"title": "some-title", "tags": []string{"go"}},
+		sparseBook(book, models.BookCriteria{Fields: []string{"title"}, Expand: []string{models.RelationTags}}))
+}
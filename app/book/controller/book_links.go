@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+// Name of book route which is reversed into hypermedia link
+const (
+	RouteBookSearch  = "book.search"
+	RouteBookTrash   = "book.trash"
+	RouteBookHistory = "book.history"
+)
+
+// Name of long-running book route which is given its own timeout by default
+const (
+	RouteBookExport = "book.export"
+	RouteBookImport = "book.import"
+	RouteBookBulk   = "book.bulk"
+)
+
+// Entity name of the related resource route
+const (
+	bookEntity     = "book"
+	authorEntity   = "author"
+	categoryEntity = "category"
+)
+
+// bookResource wrap the book response with link to itself, its collection and its related resources
+func bookResource(ctx echo.Context, book *models.Book, data interface{}) base.Resource {
+	e := ctx.Echo()
+	links := make(base.Links)
+	links.Set("self", e.Reverse(base.RouteName(bookEntity, base.ActionGet), book.ID))
+	links.Set("collection", e.Reverse(base.RouteName(bookEntity, base.ActionList)))
+	links.Set("history", e.Reverse(RouteBookHistory, book.ID))
+	for _, author := range book.Authors {
+		links.Append("authors", e.Reverse(base.RouteName(authorEntity, base.ActionGet), author.ID))
+	}
+	for _, category := range book.Categories {
+		links.Append("categories", e.Reverse(base.RouteName(categoryEntity, base.ActionGet), category.ID))
+	}
+	return base.Resource{Data: data, Links: links}
+}
+
+// Relationships map book field of the related resources to its type in JSON:API document
+func (c *InitBookController) Relationships() map[string]string {
+	return map[string]string{
+		models.RelationAuthors:    authorEntity,
+		models.RelationCategories: categoryEntity,
+	}
+}
+
+// bookResources wrap each book in the list with its links
+func bookResources(ctx echo.Context, books []*models.Book, criteria models.BookCriteria) []base.Resource {
+	resources := make([]base.Resource, len(books))
+	for i, book := range books {
+		resources[i] = bookResource(ctx, book, sparseBook(book, criteria))
+	}
+	return resources
+}
+
+// searchResources wrap each search result with links of its book
+func searchResources(ctx echo.Context, results []*models.BookSearchResult, criteria models.BookCriteria) []base.Resource {
+	resources := make([]base.Resource, len(results))
+	for i, result := range results {
+		resources[i] = bookResource(ctx, &result.Book, sparseBook(result, criteria))
+	}
+	return resources
+}
+
+// pageLinks return pagination link of the listing route
+func pageLinks(ctx echo.Context, route string, meta base.Pagination) base.Links {
+	return base.PageLinks(ctx.Echo().Reverse(route), ctx.QueryParams(), meta)
+}
+
+// cursorLinks return pagination link of the keyset listing route
+func cursorLinks(ctx echo.Context, route string, meta base.CursorPagination) base.Links {
+	return base.CursorLinks(ctx.Echo().Reverse(route), ctx.QueryParams(), meta)
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	authormodels "github.com/typical-go/typical-rest-server/app/author/models"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+func TestBookResource(t *testing.T) {
+	e := echo.New()
+	e.GET("/book", nil).Name = base.RouteName(bookEntity, base.ActionList)
+	e.GET("/book/:id", nil).Name = base.RouteName(bookEntity, base.ActionGet)
+	e.GET("/book/:id/history", nil).Name = RouteBookHistory
+	e.GET("/author/:id", nil).Name = base.RouteName(authorEntity, base.ActionGet)
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/book?page=1", nil), httptest.NewRecorder())
+
+	book := &models.Book{ID: 7, Authors: []*authormodels.Author{{ID: 1}, {ID: 2}}}
+	require.Equal(t, base.Resource{
+		Data: book,
+		Links: base.Links{
+			"self":       base.Link{Href: "/book/7"},
+			"collection": base.Link{Href: "/book"},
+			"history":    base.Link{Href: "/book/7/history"},
+			"authors":    []base.Link{{Href: "/author/1"}, {Href: "/author/2"}},
+		},
+	}, bookResource(ctx, book, book))
+
+	require.Equal(t, base.Links{
+		"self":  base.Link{Href: "/book?page=1"},
+		"first": base.Link{Href: "/book?page=1"},
+		"last":  base.Link{Href: "/book?page=2"},
+		"next":  base.Link{Href: "/book?page=2"},
+	}, pageLinks(ctx, base.RouteName(bookEntity, base.ActionList), base.Pagination{Page: 1, Size: 20, Total: 30, TotalPages: 2}))
+
+	require.Empty(t, pageLinks(ctx, RouteBookTrash, base.Pagination{Page: 1}))
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+// Format of book import and export
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+
+	mimeCSV   = "text/csv"
+	mimeJSONL = "application/x-ndjson"
+)
+
+// importBatchSize is number of books inserted in single query
+const importBatchSize = 500
+
+var csvHeader = []string{"id", "title", "author", "isbn", "published_year", "publisher", "language", "page_count",
+	"version", "updated_at", "created_at"}
+
+type (
+	bookWriter interface {
+		Write(book *models.Book) error
+		Flush() error
+	}
+	bookReader interface {
+		// Read return next book and its row number; return io.EOF when no more row
+		Read() (row int, book models.Book, err error)
+	}
+	csvBookWriter struct {
+		*csv.Writer
+	}
+	jsonlBookWriter struct {
+		*bufio.Writer
+		encoder *json.Encoder
+	}
+	csvBookReader struct {
+		*csv.Reader
+		columns map[string]int
+		row     int
+	}
+	jsonlBookReader struct {
+		*bufio.Scanner
+		row int
+	}
+)
+
+func newBookWriter(format string, w io.Writer) (bookWriter, error) {
+	switch format {
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		return &csvBookWriter{Writer: writer}, writer.Write(csvHeader)
+	case formatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlBookWriter{Writer: buffered, encoder: json.NewEncoder(buffered)}, nil
+	}
+	return nil, fmt.Errorf("unsupported format '%s'", format)
+}
+
+func newBookReader(format string, r io.Reader) (bookReader, error) {
+	switch format {
+	case formatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		header, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("missing csv header: %w", err)
+		}
+
+		columns := make(map[string]int)
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for _, required := range []string{"title", "author"} {
+			if _, ok := columns[required]; !ok {
+				return nil, fmt.Errorf("missing '%s' column in csv header", required)
+			}
+		}
+		return &csvBookReader{Reader: reader, columns: columns, row: 1}, nil
+	case formatJSONL:
+		return &jsonlBookReader{Scanner: bufio.NewScanner(r)}, nil
+	}
+	return nil, fmt.Errorf("unsupported format '%s'", format)
+}
+
+func (w *csvBookWriter) Write(book *models.Book) error {
+	return w.Writer.Write([]string{
+		strconv.FormatInt(book.ID, 10),
+		book.Title,
+		book.Author,
+		book.ISBN,
+		strconv.Itoa(book.PublishedYear),
+		book.Publisher,
+		book.Language,
+		strconv.Itoa(book.PageCount),
+		strconv.FormatInt(book.Version, 10),
+		book.UpdatedAt.Format(time.RFC3339),
+		book.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+func (w *csvBookWriter) Flush() error {
+	w.Writer.Flush()
+	return w.Writer.Error()
+}
+
+func (w *jsonlBookWriter) Write(book *models.Book) error {
+	return w.encoder.Encode(book)
+}
+
+func (r *csvBookReader) Read() (row int, book models.Book, err error) {
+	record, err := r.Reader.Read()
+	r.row++
+	if err != nil {
+		return r.row, book, err
+	}
+
+	book.Title = r.value(record, "title")
+	book.Author = r.value(record, "author")
+	book.ISBN = r.value(record, "isbn")
+	book.Publisher = r.value(record, "publisher")
+	book.Language = r.value(record, "language")
+	if book.PublishedYear, err = r.intValue(record, "published_year"); err != nil {
+		return r.row, book, err
+	}
+	if book.PageCount, err = r.intValue(record, "page_count"); err != nil {
+		return r.row, book, err
+	}
+	return r.row, book, nil
+}
+
+func (r *csvBookReader) intValue(record []string, column string) (int, error) {
+	s := r.value(record, column)
+	if s == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &csv.ParseError{StartLine: r.row, Line: r.row, Column: r.columns[column] + 1, Err: fmt.Errorf("%s must be a number", column)}
+	}
+	return i, nil
+}
+
+func (r *csvBookReader) value(record []string, column string) string {
+	if i, ok := r.columns[column]; ok && i < len(record) {
+		return strings.TrimSpace(record[i])
+	}
+	return ""
+}
+
+func (r *jsonlBookReader) Read() (row int, book models.Book, err error) {
+	for r.Scan() {
+		r.row++
+		line := bytes.TrimSpace(r.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		err = json.Unmarshal(line, &book)
+		book.ID = 0
+		return r.row, book, err
+	}
+
+	if err = r.Err(); err == nil {
+		err = io.EOF
+	}
+	return r.row, book, err
+}
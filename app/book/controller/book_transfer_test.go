@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+func TestBookWriter(t *testing.T) {
+	now := time.Date(2019, 10, 1, 8, 30, 0, 0, time.UTC)
+	book := &models.Book{ID: 1, Title: "some, title", Author: "some-author", ISBN: "9780306406157", PublishedYear: 1999,
+		Publisher: "some-publisher", Language: "en", PageCount: 300, Version: 2, UpdatedAt: now, CreatedAt: now}
+
+	testcases := []struct {
+		format   string
+		expected string
+	}{
+		{formatCSV, "id,title,author,isbn,published_year,publisher,language,page_count,version,updated_at,created_at\n" +
+			"1,\"some, title\",some-author,9780306406157,1999,some-publisher,en,300,2,2019-10-01T08:30:00Z,2019-10-01T08:30:00Z\n"},
+		{formatJSONL, `{"id":1,"title":"some, title","author":"some-author","isbn":"9780306406157","published_year":1999,` +
+			`"publisher":"some-publisher","language":"en","page_count":300,"version":2}` + "\n"},
+	}
+
+	for _, tt := range testcases {
+		var buf bytes.Buffer
+		writer, err := newBookWriter(tt.format, &buf)
+		require.NoError(t, err)
+		require.NoError(t, writer.Write(book))
+		require.NoError(t, writer.Flush())
+		require.Equal(t, tt.expected, buf.String())
+	}
+
+	_, err := newBookWriter("xml", &bytes.Buffer{})
+	require.EqualError(t, err, "unsupported format 'xml'")
+}
+
+func TestBookReader(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		reader, err := newBookReader(formatCSV, strings.NewReader("author,title,page_count\nsome-author, some-title ,120\n"+
+			"some-author,some-title,many\n\"broken\n"))
+		require.NoError(t, err)
+
+		row, book, err := reader.Read()
+		require.NoError(t, err)
+		require.Equal(t, 2, row)
+		require.Equal(t, models.Book{Title: "some-title", Author: "some-author", PageCount: 120}, book)
+
+		row, _, err = reader.Read()
+		require.EqualError(t, err, "parse error on line 3, column 3: page_count must be a number")
+		require.Equal(t, 3, row)
+
+		_, _, err = reader.Read()
+		require.Error(t, err)
+
+		_, _, err = reader.Read()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("csv without required column", func(t *testing.T) {
+		_, err := newBookReader(formatCSV, strings.NewReader("title\nsome-title\n"))
+		require.EqualError(t, err, "missing 'author' column in csv header")
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		reader, err := newBookReader(formatJSONL, strings.NewReader(`{"id":9,"title":"some-title","author":"some-author"}`+"\n\n{bad\n"))
+		require.NoError(t, err)
+
+		row, book, err := reader.Read()
+		require.NoError(t, err)
+		require.Equal(t, 1, row)
+		require.Equal(t, models.Book{Title: "some-title", Author: "some-author"}, book)
+
+		row, _, err = reader.Read()
+		require.Error(t, err)
+		require.Equal(t, 3, row)
+
+		_, _, err = reader.Read()
+		require.Equal(t, io.EOF, err)
+	})
+}
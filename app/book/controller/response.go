@@ -1,36 +1,63 @@
 package controller
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
 )
 
 const (
-	underConstrucionStatus = http.StatusServiceUnavailable
-	invalidMessageStatus   = http.StatusBadRequest
-	invalidIDStatus        = http.StatusBadRequest
-	insertSuccessStatus    = http.StatusCreated
+	preconditionStatus = http.StatusPreconditionFailed
 )
 
-func invalidMessage(ctx echo.Context, err error) error {
-	res := map[string]interface{}{}
-	res["message"] = "Invalid Message"
+// Conditional request header
+const (
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+	headerETag        = "ETag"
 
-	return ctx.JSON(invalidMessageStatus, res)
-}
+	headerCacheControl = "Cache-Control"
+	coverCacheControl  = "public, max-age=86400"
+)
 
-func invalidID(ctx echo.Context, err error) error {
-	res := map[string]interface{}{}
-	res["message"] = "Invalid ID"
+func invalidMessage(err error) error {
+	return base.NewValidationError(err)
+}
 
-	return ctx.JSON(invalidIDStatus, res)
+func invalidID(err error) error {
+	return base.InvalidID(err)
 }
 
 func insertSuccess(ctx echo.Context, lastInsertID int64) error {
-	res := map[string]interface{}{}
-	res["message"] = fmt.Sprintf("Success insert new record #%d", lastInsertID)
-	res["data"] = lastInsertID
-	return ctx.JSON(insertSuccessStatus, res)
+	return base.InsertSuccess(ctx, lastInsertID)
+}
+
+func notFound(format string, args ...interface{}) error {
+	return base.NewNotFoundError(format, args...)
+}
+
+func preconditionFailed() error {
+	return base.NewError(preconditionStatus, errors.New("book has been modified, please fetch the latest version"))
+}
+
+func conflict(err error) error {
+	return base.NewConflictError(err)
+}
+
+// describeError return detail of the error and its invalid fields translated into language accepted by the request
+func describeError(ctx echo.Context, err error) (string, []validkit.FieldError) {
+	fieldErrs := validkit.FieldErrors(err, validkit.Translator(ctx.Request().Header.Get("Accept-Language")))
+	if len(fieldErrs) == 0 {
+		return err.Error(), nil
+	}
+
+	messages := make([]string, len(fieldErrs))
+	for i, fieldErr := range fieldErrs {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; "), fieldErrs
 }
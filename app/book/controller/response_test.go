@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+)
+
+func TestDescribeError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/book/import", nil)
+	req.Header.Set("Accept-Language", "id")
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+	t.Run("validation error", func(t *testing.T) {
+		book := models.Book{Author: "some-author"}
+		detail, fieldErrs := describeError(ctx, book.Validate())
+		require.Equal(t, "title wajib diisi", detail)
+		require.Len(t, fieldErrs, 1)
+		require.Equal(t, "title", fieldErrs[0].Field)
+		require.Equal(t, "required", fieldErrs[0].Rule)
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		detail, fieldErrs := describeError(ctx, errors.New("some-error"))
+		require.Equal(t, "some-error", detail)
+		require.Nil(t, fieldErrs)
+	})
+}
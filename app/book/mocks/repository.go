@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/typical-go/typical-rest-server/app/book/models"
@@ -13,12 +14,12 @@ type Repository struct {
 }
 
 //Find func
-func (r Repository) Find(id int64) (*models.Book, error) {
-	arg := r.Mock.Called(id)
+func (r Repository) Find(ctx context.Context, id int64) (*models.Book, error) {
+	arg := r.Mock.Called(ctx, id)
 
 	var book *models.Book
-	if result, ok := arg.Get(0).(func(int64) *models.Book); ok {
-		book = result(id)
+	if result, ok := arg.Get(0).(func(context.Context, int64) *models.Book); ok {
+		book = result(ctx, id)
 	} else {
 		if arg.Get(0) != nil {
 			book, _ = arg.Get(0).(*models.Book)
@@ -26,8 +27,8 @@ func (r Repository) Find(id int64) (*models.Book, error) {
 	}
 
 	var err error
-	if result, ok := arg.Get(1).(func(int64) error); ok {
-		err = result(id)
+	if result, ok := arg.Get(1).(func(context.Context, int64) error); ok {
+		err = result(ctx, id)
 	} else {
 		err = arg.Error(1)
 	}
@@ -36,12 +37,12 @@ func (r Repository) Find(id int64) (*models.Book, error) {
 }
 
 //List func
-func (r Repository) List() ([]*models.Book, error) {
-	arg := r.Mock.Called()
+func (r Repository) List(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, error) {
+	arg := r.Mock.Called(ctx, criteria)
 
 	var book []*models.Book
-	if result, ok := arg.Get(0).([]*models.Book); ok {
-		book = result
+	if result, ok := arg.Get(0).(func(context.Context, models.BookCriteria) []*models.Book); ok {
+		book = result(ctx, criteria)
 	} else {
 		if arg.Get(0) != nil {
 			book, _ = arg.Get(0).([]*models.Book)
@@ -49,8 +50,8 @@ func (r Repository) List() ([]*models.Book, error) {
 	}
 
 	var err error
-	if result, ok := arg.Get(1).(error); ok {
-		err = result
+	if result, ok := arg.Get(1).(func(context.Context, models.BookCriteria) error); ok {
+		err = result(ctx, criteria)
 	} else {
 		err = arg.Error(1)
 	}
@@ -58,6 +59,27 @@ func (r Repository) List() ([]*models.Book, error) {
 	return book, err
 }
 
+//Count func
+func (r Repository) Count(ctx context.Context, criteria models.BookCriteria) (int64, error) {
+	arg := r.Mock.Called(ctx, criteria)
+
+	var total int64
+	if result, ok := arg.Get(0).(func(context.Context, models.BookCriteria) int64); ok {
+		total = result(ctx, criteria)
+	} else {
+		total = arg.Get(0).(int64)
+	}
+
+	var err error
+	if result, ok := arg.Get(1).(func(context.Context, models.BookCriteria) error); ok {
+		err = result(ctx, criteria)
+	} else {
+		err = arg.Error(1)
+	}
+
+	return total, err
+}
+
 //Insert func
 func (r Repository) Insert(ctx context.Context, book interface{}) (int64, error) {
 	bookParse, _ := book.(*models.Book)
@@ -95,8 +117,36 @@ func (r Repository) Update(ctx context.Context, book interface{}) error {
 	return err
 }
 
+//Patch func
+func (r Repository) Patch(ctx context.Context, book models.Book, columns []string) error {
+	arg := r.Mock.Called(ctx, book, columns)
+
+	var err error
+	if result, ok := arg.Get(0).(func(context.Context, models.Book, []string) error); ok {
+		err = result(ctx, book, columns)
+	} else {
+		err = arg.Error(0)
+	}
+
+	return err
+}
+
 //Delete func
-func (r Repository) Delete(ctx context.Context, id int64) error {
+func (r Repository) Delete(ctx context.Context, id int64, version int64) error {
+	arg := r.Mock.Called(ctx, id, version)
+
+	var err error
+	if result, ok := arg.Get(0).(func(context.Context, int64, int64) error); ok {
+		err = result(ctx, id, version)
+	} else {
+		err = arg.Error(0)
+	}
+
+	return err
+}
+
+//Restore func
+func (r Repository) Restore(ctx context.Context, id int64) error {
 	arg := r.Mock.Called(ctx, id)
 
 	var err error
@@ -108,3 +158,135 @@ func (r Repository) Delete(ctx context.Context, id int64) error {
 
 	return err
 }
+
+//Purge func
+func (r Repository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	arg := r.Mock.Called(ctx, deletedBefore)
+
+	var purged int64
+	if result, ok := arg.Get(0).(func(context.Context, time.Time) int64); ok {
+		purged = result(ctx, deletedBefore)
+	} else {
+		purged = arg.Get(0).(int64)
+	}
+
+	return purged, arg.Error(1)
+}
+
+//InsertBatch func
+func (r Repository) InsertBatch(ctx context.Context, books []models.Book) ([]int64, error) {
+	arg := r.Mock.Called(ctx, books)
+
+	var ids []int64
+	if arg.Get(0) != nil {
+		ids, _ = arg.Get(0).([]int64)
+	}
+
+	return ids, arg.Error(1)
+}
+
+//Stream func
+func (r Repository) Stream(ctx context.Context, criteria models.BookCriteria, fn func(*models.Book) error) error {
+	arg := r.Mock.Called(ctx, criteria, fn)
+	return arg.Error(0)
+}
+
+//Search func
+func (r Repository) Search(ctx context.Context, query string, criteria models.BookCriteria) ([]*models.BookSearchResult, int64, error) {
+	arg := r.Mock.Called(ctx, query, criteria)
+
+	var results []*models.BookSearchResult
+	if arg.Get(0) != nil {
+		results, _ = arg.Get(0).([]*models.BookSearchResult)
+	}
+
+	var total int64
+	if arg.Get(1) != nil {
+		total, _ = arg.Get(1).(int64)
+	}
+
+	return results, total, arg.Error(2)
+}
+
+//FindByISBN func
+func (r Repository) FindByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	arg := r.Mock.Called(ctx, isbn)
+
+	var book *models.Book
+	if arg.Get(0) != nil {
+		book, _ = arg.Get(0).(*models.Book)
+	}
+
+	return book, arg.Error(1)
+}
+
+//ListTags func
+func (r Repository) ListTags(ctx context.Context, bookIDs []int64) (map[int64][]string, error) {
+	arg := r.Mock.Called(ctx, bookIDs)
+
+	var tags map[int64][]string
+	if arg.Get(0) != nil {
+		tags, _ = arg.Get(0).(map[int64][]string)
+	}
+
+	return tags, arg.Error(1)
+}
+
+//AddTags func
+func (r Repository) AddTags(ctx context.Context, bookID int64, tags []string) error {
+	arg := r.Mock.Called(ctx, bookID, tags)
+	return arg.Error(0)
+}
+
+//RemoveTags func
+func (r Repository) RemoveTags(ctx context.Context, bookID int64, tags []string) error {
+	arg := r.Mock.Called(ctx, bookID, tags)
+	return arg.Error(0)
+}
+
+//History func
+func (r Repository) History(ctx context.Context, bookID int64, criteria models.BookCriteria) ([]*models.BookAudit, int64, error) {
+	arg := r.Mock.Called(ctx, bookID, criteria)
+
+	var audits []*models.BookAudit
+	if arg.Get(0) != nil {
+		audits, _ = arg.Get(0).([]*models.BookAudit)
+	}
+
+	var total int64
+	if arg.Get(1) != nil {
+		total, _ = arg.Get(1).(int64)
+	}
+
+	return audits, total, arg.Error(2)
+}
+
+//FindRevision func
+func (r Repository) FindRevision(ctx context.Context, bookID int64, revision int64) (*models.BookAudit, error) {
+	arg := r.Mock.Called(ctx, bookID, revision)
+
+	var audit *models.BookAudit
+	if arg.Get(0) != nil {
+		audit, _ = arg.Get(0).(*models.BookAudit)
+	}
+
+	return audit, arg.Error(1)
+}
+
+//Revert func
+func (r Repository) Revert(ctx context.Context, book models.Book) error {
+	arg := r.Mock.Called(ctx, book)
+	return arg.Error(0)
+}
+
+//Upsert func
+func (r Repository) Upsert(ctx context.Context, key models.NaturalKey, book models.Book) (int64, bool, error) {
+	arg := r.Mock.Called(ctx, key, book)
+
+	var id int64
+	if arg.Get(0) != nil {
+		id, _ = arg.Get(0).(int64)
+	}
+
+	return id, arg.Bool(1), arg.Error(2)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/typical-go/typical-rest-server/app/book/models"
@@ -13,12 +14,12 @@ type Service struct {
 }
 
 //GetBook func
-func (s Service) GetBook(id int64) (*models.Book, error) {
-	arg := s.Mock.Called(id)
+func (s Service) GetBook(ctx context.Context, id int64) (*models.Book, error) {
+	arg := s.Mock.Called(ctx, id)
 
 	var book *models.Book
-	if result, ok := arg.Get(0).(func(int64) *models.Book); ok {
-		book = result(id)
+	if result, ok := arg.Get(0).(func(context.Context, int64) *models.Book); ok {
+		book = result(ctx, id)
 	} else {
 		if arg.Get(0) != nil {
 			book, _ = arg.Get(0).(*models.Book)
@@ -26,8 +27,8 @@ func (s Service) GetBook(id int64) (*models.Book, error) {
 	}
 
 	var err error
-	if result, ok := arg.Get(1).(func(int64) error); ok {
-		err = result(id)
+	if result, ok := arg.Get(1).(func(context.Context, int64) error); ok {
+		err = result(ctx, id)
 	} else {
 		err = arg.Error(1)
 	}
@@ -36,26 +37,82 @@ func (s Service) GetBook(id int64) (*models.Book, error) {
 }
 
 //ListBook func
-func (s Service) ListBook() ([]*models.Book, error) {
-	arg := s.Mock.Called()
+func (s Service) ListBook(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, int64, error) {
+	arg := s.Mock.Called(ctx, criteria)
 
 	var book []*models.Book
-	if result, ok := arg.Get(0).([]*models.Book); ok {
-		book = result
+	if result, ok := arg.Get(0).(func(context.Context, models.BookCriteria) []*models.Book); ok {
+		book = result(ctx, criteria)
 	} else {
 		if arg.Get(0) != nil {
 			book, _ = arg.Get(0).([]*models.Book)
 		}
 	}
 
+	var total int64
+	if result, ok := arg.Get(1).(func(context.Context, models.BookCriteria) int64); ok {
+		total = result(ctx, criteria)
+	} else {
+		total = arg.Get(1).(int64)
+	}
+
 	var err error
-	if result, ok := arg.Get(1).(error); ok {
-		err = result
+	if result, ok := arg.Get(2).(func(context.Context, models.BookCriteria) error); ok {
+		err = result(ctx, criteria)
 	} else {
-		err = arg.Error(1)
+		err = arg.Error(2)
 	}
 
-	return book, err
+	return book, total, err
+}
+
+//ListBookByCursor func
+func (s Service) ListBookByCursor(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, *models.Cursor, error) {
+	arg := s.Mock.Called(ctx, criteria)
+
+	var book []*models.Book
+	if result, ok := arg.Get(0).(func(context.Context, models.BookCriteria) []*models.Book); ok {
+		book = result(ctx, criteria)
+	} else {
+		if arg.Get(0) != nil {
+			book, _ = arg.Get(0).([]*models.Book)
+		}
+	}
+
+	var cursor *models.Cursor
+	if result, ok := arg.Get(1).(func(context.Context, models.BookCriteria) *models.Cursor); ok {
+		cursor = result(ctx, criteria)
+	} else {
+		if arg.Get(1) != nil {
+			cursor, _ = arg.Get(1).(*models.Cursor)
+		}
+	}
+
+	var err error
+	if result, ok := arg.Get(2).(func(context.Context, models.BookCriteria) error); ok {
+		err = result(ctx, criteria)
+	} else {
+		err = arg.Error(2)
+	}
+
+	return book, cursor, err
+}
+
+//SearchBook func
+func (s Service) SearchBook(ctx context.Context, query string, criteria models.BookCriteria) ([]*models.BookSearchResult, int64, error) {
+	arg := s.Mock.Called(ctx, query, criteria)
+
+	var results []*models.BookSearchResult
+	if arg.Get(0) != nil {
+		results, _ = arg.Get(0).([]*models.BookSearchResult)
+	}
+
+	var total int64
+	if arg.Get(1) != nil {
+		total, _ = arg.Get(1).(int64)
+	}
+
+	return results, total, arg.Error(2)
 }
 
 //CreateBook func
@@ -95,8 +152,36 @@ func (s Service) UpdateBook(ctx context.Context, book interface{}) error {
 	return err
 }
 
+//PatchBook func
+func (s Service) PatchBook(ctx context.Context, book models.Book, columns []string) error {
+	arg := s.Mock.Called(ctx, book, columns)
+
+	var err error
+	if result, ok := arg.Get(0).(func(context.Context, models.Book, []string) error); ok {
+		err = result(ctx, book, columns)
+	} else {
+		err = arg.Error(0)
+	}
+
+	return err
+}
+
 //DeleteBook func
-func (s Service) DeleteBook(ctx context.Context, id int64) error {
+func (s Service) DeleteBook(ctx context.Context, id int64, version int64) error {
+	arg := s.Mock.Called(ctx, id, version)
+
+	var err error
+	if result, ok := arg.Get(0).(func(context.Context, int64, int64) error); ok {
+		err = result(ctx, id, version)
+	} else {
+		err = arg.Error(0)
+	}
+
+	return err
+}
+
+//RestoreBook func
+func (s Service) RestoreBook(ctx context.Context, id int64) error {
 	arg := s.Mock.Called(ctx, id)
 
 	var err error
@@ -108,3 +193,129 @@ func (s Service) DeleteBook(ctx context.Context, id int64) error {
 
 	return err
 }
+
+//PurgeBook func
+func (s Service) PurgeBook(ctx context.Context, retention time.Duration) (int64, error) {
+	arg := s.Mock.Called(ctx, retention)
+
+	var purged int64
+	if result, ok := arg.Get(0).(func(context.Context, time.Duration) int64); ok {
+		purged = result(ctx, retention)
+	} else {
+		purged = arg.Get(0).(int64)
+	}
+
+	return purged, arg.Error(1)
+}
+
+//BulkBook func
+func (s Service) BulkBook(ctx context.Context, request models.BulkRequest) ([]*models.BulkResult, bool) {
+	arg := s.Mock.Called(ctx, request)
+
+	var results []*models.BulkResult
+	if arg.Get(0) != nil {
+		results, _ = arg.Get(0).([]*models.BulkResult)
+	}
+
+	return results, arg.Bool(1)
+}
+
+//ImportBook func
+func (s Service) ImportBook(ctx context.Context, books []models.Book) ([]int64, []error) {
+	arg := s.Mock.Called(ctx, books)
+
+	var ids []int64
+	if arg.Get(0) != nil {
+		ids, _ = arg.Get(0).([]int64)
+	}
+
+	var errs []error
+	if arg.Get(1) != nil {
+		errs, _ = arg.Get(1).([]error)
+	}
+
+	return ids, errs
+}
+
+//ExportBook func
+func (s Service) ExportBook(ctx context.Context, criteria models.BookCriteria, fn func(*models.Book) error) error {
+	arg := s.Mock.Called(ctx, criteria, fn)
+	return arg.Error(0)
+}
+
+//GetBookByISBN func
+func (s Service) GetBookByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	arg := s.Mock.Called(ctx, isbn)
+
+	var book *models.Book
+	if arg.Get(0) != nil {
+		book, _ = arg.Get(0).(*models.Book)
+	}
+
+	return book, arg.Error(1)
+}
+
+//AttachBookTags func
+func (s Service) AttachBookTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	arg := s.Mock.Called(ctx, id, tags)
+
+	var attached []string
+	if arg.Get(0) != nil {
+		attached, _ = arg.Get(0).([]string)
+	}
+
+	return attached, arg.Error(1)
+}
+
+//DetachBookTags func
+func (s Service) DetachBookTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	arg := s.Mock.Called(ctx, id, tags)
+
+	var remaining []string
+	if arg.Get(0) != nil {
+		remaining, _ = arg.Get(0).([]string)
+	}
+
+	return remaining, arg.Error(1)
+}
+
+//BookHistory func
+func (s Service) BookHistory(ctx context.Context, id int64, criteria models.BookCriteria) ([]*models.BookAudit, int64, error) {
+	arg := s.Mock.Called(ctx, id, criteria)
+
+	var audits []*models.BookAudit
+	if arg.Get(0) != nil {
+		audits, _ = arg.Get(0).([]*models.BookAudit)
+	}
+
+	var total int64
+	if arg.Get(1) != nil {
+		total, _ = arg.Get(1).(int64)
+	}
+
+	return audits, total, arg.Error(2)
+}
+
+//RevertBook func
+func (s Service) RevertBook(ctx context.Context, id int64, revision int64, version int64) (*models.Book, error) {
+	arg := s.Mock.Called(ctx, id, revision, version)
+
+	var book *models.Book
+	if arg.Get(0) != nil {
+		book, _ = arg.Get(0).(*models.Book)
+	}
+
+	return book, arg.Error(1)
+}
+
+//UpsertBook func
+func (s Service) UpsertBook(ctx context.Context, key models.NaturalKey, book models.Book) (int64, bool, error) {
+	arg := s.Mock.Called(ctx, key, book)
+
+	var id int64
+	if arg.Get(0) != nil {
+		id, _ = arg.Get(0).(int64)
+	}
+
+	return id, arg.Bool(1), arg.Error(2)
+}
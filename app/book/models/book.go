@@ -2,32 +2,106 @@ package models
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
-	validator "gopkg.in/go-playground/validator.v9"
+	authormodels "github.com/typical-go/typical-rest-server/app/author/models"
+	categorymodels "github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+// Error of book operation
+var (
+	// ErrVersionConflict returned when book is changed by other since the expected version
+	ErrVersionConflict = errors.New("book version conflict")
+	// ErrBookNotFound returned when book is not exist
+	ErrBookNotFound = errors.New("book not found")
+	// ErrDuplicateISBN returned when other book already has the same ISBN
+	ErrDuplicateISBN = errors.New("isbn already exist")
 )
 
 // Book represented database model
 type Book struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title" validate:"required"`
-	Author    string    `json:"author" validate:"required"`
-	UpdatedAt time.Time `json:"-"`
-	CreatedAt time.Time `json:"-"`
+	ID            int64      `json:"id"`
+	Title         string     `json:"title" validate:"required"`
+	Author        string     `json:"author" validate:"author_required,max=255"`
+	ISBN          string     `json:"isbn" validate:"omitempty,isbn_checksum"`
+	PublishedYear int        `json:"published_year" validate:"omitempty,min=1000,not_future_year"`
+	Publisher     string     `json:"publisher" validate:"max=255"`
+	Language      string     `json:"language" validate:"omitempty,language_code"`
+	PageCount     int        `json:"page_count" validate:"min=0"`
+	Version       int64      `json:"version"`
+	UpdatedAt     time.Time  `json:"-"`
+	CreatedAt     time.Time  `json:"-"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+
+	// AuthorIDs link the book to the authors in order when given on create or update; otherwise the book is linked to the author
+	// with the same name as Author. Author is always derived from names of the linked authors
+	AuthorIDs []int64                `json:"author_ids,omitempty"`
+	Authors   []*authormodels.Author `json:"authors,omitempty"`
+
+	// CategoryIDs replace categories of the book when given on create or update
+	CategoryIDs []int64                    `json:"category_ids,omitempty"`
+	Categories  []*categorymodels.Category `json:"categories,omitempty"`
+
+	// Tags is attached and detached through its own endpoint
+	Tags []string `json:"tags,omitempty"`
 }
 
 //ScanBook func
 func ScanBook(rows *sql.Rows) (*Book, error) {
 	var book Book
-	err := rows.Scan(&book.ID, &book.Title, &book.Author, &book.UpdatedAt, &book.CreatedAt)
+	err := rows.Scan(book.Fields()...)
 	if err != nil {
 		return nil, err
 	}
 	return &book, nil
 }
 
+// Fields return pointer of book fields as scan destination in the same order with the book columns
+func (b *Book) Fields() []interface{} {
+	return []interface{}{&b.ID, &b.Title, &b.Author, &b.ISBN, &b.PublishedYear, &b.Publisher, &b.Language, &b.PageCount,
+		&b.Version, &b.UpdatedAt, &b.CreatedAt, &b.DeletedAt}
+}
+
+// ColumnValue return value of the column
+func (b *Book) ColumnValue(column string) interface{} {
+	switch column {
+	case "id":
+		return b.ID
+	case "title":
+		return b.Title
+	case "author":
+		return b.Author
+	case "isbn":
+		return NormalizeISBN(b.ISBN)
+	case "published_year":
+		return b.PublishedYear
+	case "publisher":
+		return b.Publisher
+	case "language":
+		return b.Language
+	case "page_count":
+		return b.PageCount
+	case "version":
+		return b.Version
+	case "updated_at":
+		return b.UpdatedAt
+	case "created_at":
+		return b.CreatedAt
+	case "deleted_at":
+		return b.DeletedAt
+	}
+	return nil
+}
+
+// ETag return weak entity tag of current version; it is weak since every representation of the book share the version
+func (b *Book) ETag() string {
+	return fmt.Sprintf(`W/"%d"`, b.Version)
+}
+
 // Validate book
 func (b *Book) Validate() error {
-	validate := validator.New()
-	return validate.Struct(b)
+	return validkit.Struct(b)
 }
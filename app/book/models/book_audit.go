@@ -0,0 +1,82 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Action recorded in book audit
+const (
+	AuditInsert  = "insert"
+	AuditUpdate  = "update"
+	AuditPatch   = "patch"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+	AuditRevert  = "revert"
+)
+
+// ErrRevisionNotFound returned when the revision is not exist or has no book value to revert to
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// BookAudit is a change of book; old value is null on insert and new value is null on purge
+type BookAudit struct {
+	Revision  int64           `json:"revision"`
+	BookID    int64           `json:"book_id"`
+	Action    string          `json:"action"`
+	OldValue  json.RawMessage `json:"old_value"`
+	NewValue  json.RawMessage `json:"new_value"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+//ScanBookAudit func
+func ScanBookAudit(rows *sql.Rows) (*BookAudit, error) {
+	var audit BookAudit
+	err := rows.Scan(audit.Fields()...)
+	if err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// Fields return pointer of audit fields as scan destination in the same order with the audit columns;
+// the json values is scanned as bytes so null value is allowed
+func (a *BookAudit) Fields() []interface{} {
+	return []interface{}{&a.Revision, &a.BookID, &a.Action, (*[]byte)(&a.OldValue), (*[]byte)(&a.NewValue), &a.Actor, &a.RequestID, &a.CreatedAt}
+}
+
+// Book return value of the book after the change; only the columns given by client is restored
+func (a *BookAudit) Book() (*Book, error) {
+	if len(a.NewValue) == 0 || string(a.NewValue) == "null" {
+		return nil, ErrRevisionNotFound
+	}
+
+	// NOTE: timestamp in audit value has no time zone so it is not decoded
+	var value struct {
+		Title         string `json:"title"`
+		Author        string `json:"author"`
+		ISBN          string `json:"isbn"`
+		PublishedYear int    `json:"published_year"`
+		Publisher     string `json:"publisher"`
+		Language      string `json:"language"`
+		PageCount     int    `json:"page_count"`
+	}
+	if err := json.Unmarshal(a.NewValue, &value); err != nil {
+		return nil, err
+	}
+
+	return &Book{
+		ID:            a.BookID,
+		Title:         value.Title,
+		Author:        value.Author,
+		ISBN:          value.ISBN,
+		PublishedYear: value.PublishedYear,
+		Publisher:     value.Publisher,
+		Language:      value.Language,
+		PageCount:     value.PageCount,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookAudit_Book(t *testing.T) {
+	t.Run("revision value", func(t *testing.T) {
+		audit := BookAudit{
+			BookID: 7,
+			NewValue: json.RawMessage(`{"id": 7, "title": "some-title", "author": "some-author", "isbn": "9780261103344", ` +
+				`"published_year": 1937, "publisher": "", "language": "en", "page_count": 310, "version": 3, ` +
+				`"updated_at": "2020-01-02T03:04:05.123456", "deleted_at": null}`),
+		}
+		book, err := audit.Book()
+		require.NoError(t, err)
+		require.Equal(t, &Book{ID: 7, Title: "some-title", Author: "some-author", ISBN: "9780261103344",
+			PublishedYear: 1937, Language: "en", PageCount: 310}, book)
+	})
+
+	t.Run("purged", func(t *testing.T) {
+		audit := BookAudit{BookID: 7, Action: AuditPurge}
+		_, err := audit.Book()
+		require.Equal(t, ErrRevisionNotFound, err)
+	})
+}
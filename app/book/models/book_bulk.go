@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+// Bulk operation type
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// Bulk mode
+const (
+	// BulkAtomic commit all operations or nothing
+	BulkAtomic = "atomic"
+	// BulkBestEffort commit the succeed operations and skip the failed one
+	BulkBestEffort = "best_effort"
+)
+
+// Bulk result status
+const (
+	BulkCreated    = "created"
+	BulkUpdated    = "updated"
+	BulkDeleted    = "deleted"
+	BulkInvalid    = "invalid"
+	BulkFailed     = "failed"
+	BulkNotFound   = "not_found"
+	BulkRolledBack = "rolled_back"
+	BulkSkipped    = "skipped"
+)
+
+// MaxBulkOperations is maximum number of operations in single bulk request
+const MaxBulkOperations = 1000
+
+// BulkRequest is list of book operations executed in single transaction
+type BulkRequest struct {
+	Mode       string          `json:"mode"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkOperation is single create, update or delete operation
+type BulkOperation struct {
+	Op   string `json:"op"`
+	Book Book   `json:"book"`
+}
+
+// BulkResult is result of single bulk operation
+type BulkResult struct {
+	Index  int                   `json:"index"`
+	Op     string                `json:"op"`
+	ID     int64                 `json:"id,omitempty"`
+	Status string                `json:"status"`
+	Detail string                `json:"detail,omitempty"`
+	Errors []validkit.FieldError `json:"errors,omitempty"`
+
+	// Err failed the operation; it is described into Detail and Errors in the language of the request
+	Err error `json:"-" xml:"-"`
+}
+
+// Validate bulk request
+func (r *BulkRequest) Validate() error {
+	if r.Mode == "" {
+		r.Mode = BulkAtomic
+	}
+	if r.Mode != BulkAtomic && r.Mode != BulkBestEffort {
+		return fmt.Errorf("mode must be either '%s' or '%s'", BulkAtomic, BulkBestEffort)
+	}
+	if len(r.Operations) == 0 {
+		return errors.New("operations is required")
+	}
+	if len(r.Operations) > MaxBulkOperations {
+		return fmt.Errorf("operations must be at most %d", MaxBulkOperations)
+	}
+	return nil
+}
+
+// Validate bulk operation
+func (o *BulkOperation) Validate() error {
+	switch o.Op {
+	case BulkCreate:
+		return o.Book.Validate()
+	case BulkUpdate:
+		if o.Book.ID <= 0 {
+			return errors.New("book.id is required")
+		}
+		return o.Book.Validate()
+	case BulkDelete:
+		if o.Book.ID <= 0 {
+			return errors.New("book.id is required")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown op '%s'", o.Op)
+}
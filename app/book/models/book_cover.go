@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error of book cover operation
+var (
+	// ErrCoverTooLarge returned when uploaded cover exceed maximum size
+	ErrCoverTooLarge = errors.New("cover image is too large")
+	// ErrUnsupportedCover returned when uploaded cover is neither JPEG nor PNG image
+	ErrUnsupportedCover = errors.New("cover must be JPEG or PNG image")
+	// ErrCoverNotFound returned when book has no cover
+	ErrCoverNotFound = errors.New("cover not found")
+	// ErrInvalidCoverSize returned when requested cover size is unknown
+	ErrInvalidCoverSize = errors.New("invalid cover size")
+)
+
+// CoverMaxPixels is maximum width × height of uploaded cover; the dimension is checked before decoding so small file with huge dimension is not decoded
+const CoverMaxPixels = 25 * 1000 * 1000
+
+// CoverOriginal is size of cover as uploaded
+const CoverOriginal = "original"
+
+// CoverThumbnails is width in pixel of generated cover thumbnail by its size name
+var CoverThumbnails = map[string]int{
+	"small":  150,
+	"medium": 400,
+}
+
+// CoverKey return storage key of the book cover in the size
+func CoverKey(bookID int64, size string) string {
+	return fmt.Sprintf("book/%d/cover/%s", bookID, size)
+}
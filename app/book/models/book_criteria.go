@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Default and maximum number of books in a single page
+const (
+	DefaultPageSize uint64 = 20
+	MaxPageSize     uint64 = 100
+)
+
+// ErrInvalidSort returned when sort refer to unknown column
+var ErrInvalidSort = errors.New("invalid sort column")
+
+// BookCriteria to filter, sort and paginate book listing
+type BookCriteria struct {
+	Title      string
+	TitleLike  string
+	Author     string
+	AuthorLike string
+
+	// AuthorID filter books which linked to the author
+	AuthorID int64
+
+	// Tags filter books which has all the tags
+	Tags []string
+
+	// CategoryID filter books in the category or any of its descendants
+	CategoryID int64
+
+	// Trashed list soft deleted books instead of the active one
+	Trashed bool
+
+	// Sort is comma separated column names, prefix with "-" for descending order (e.g. "author,-title")
+	Sort string
+
+	Page uint64
+	Size uint64
+
+	// Keyset paginate by sort column and id instead of page; After is cursor of last book in previous page
+	Keyset bool
+	After  *Cursor
+
+	// Fields select only the book fields; all fields when empty
+	Fields []string
+
+	// Expand embed only the relations; all relations when nil
+	Expand []string
+}
+
+// Offset of first book in the page
+func (c BookCriteria) Offset() uint64 {
+	if c.Page <= 1 {
+		return 0
+	}
+	return (c.Page - 1) * c.Size
+}
+
+// TotalPages return number of pages needed to show total books
+func (c BookCriteria) TotalPages(total int64) int64 {
+	if c.Size == 0 {
+		return 0
+	}
+	size := int64(c.Size)
+	return (total + size - 1) / size
+}
+
+// Selects return whether the field is selected
+func (c BookCriteria) Selects(field string) bool {
+	return len(c.Fields) == 0 || containsName(c.Fields, field)
+}
+
+// Expands return whether the relation is embedded
+func (c BookCriteria) Expands(relation string) bool {
+	return c.Expand == nil || containsName(c.Expand, relation)
+}
+
+// KeysetSort return sort column and direction of keyset pagination; sorted by id when sort is not defined
+func (c BookCriteria) KeysetSort() (column string, desc bool) {
+	sort := strings.TrimSpace(c.Sort)
+	switch {
+	case sort == "":
+		return "id", false
+	case strings.HasPrefix(sort, "-"):
+		return sort[1:], true
+	}
+	return sort, false
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookCriteria_Offset(t *testing.T) {
+	testcases := []struct {
+		criteria BookCriteria
+		offset   uint64
+	}{
+		{BookCriteria{Page: 0, Size: 20}, 0},
+		{BookCriteria{Page: 1, Size: 20}, 0},
+		{BookCriteria{Page: 3, Size: 20}, 40},
+	}
+
+	for _, tt := range testcases {
+		require.Equal(t, tt.offset, tt.criteria.Offset())
+	}
+}
+
+func TestBookCriteria_TotalPages(t *testing.T) {
+	testcases := []struct {
+		size       uint64
+		total      int64
+		totalPages int64
+	}{
+		{20, 0, 0},
+		{20, 20, 1},
+		{20, 21, 2},
+		{0, 21, 0},
+	}
+
+	for _, tt := range testcases {
+		require.Equal(t, tt.totalPages, BookCriteria{Size: tt.size}.TotalPages(tt.total))
+	}
+}
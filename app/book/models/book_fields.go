@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Relation of book which is embedded in the response
+const (
+	RelationAuthors    = "authors"
+	RelationCategories = "categories"
+	RelationTags       = "tags"
+)
+
+// Error of sparse fieldset
+var (
+	// ErrUnknownField returned when selected field is not a book field
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnknownRelation returned when expanded relation is not a book relation
+	ErrUnknownRelation = errors.New("unknown relation")
+)
+
+var (
+	// BookFields is name of book fields which can be selected
+	BookFields = []string{"id", "title", "author", "isbn", "published_year", "publisher", "language", "page_count",
+		"version", "deleted_at"}
+
+	// BookRelations is name of book relations which can be expanded
+	BookRelations = []string{RelationAuthors, RelationCategories, RelationTags}
+)
+
+// ParseFields return the comma separated book fields (e.g. "id,title")
+func ParseFields(s string) ([]string, error) {
+	return parseNames(s, BookFields, ErrUnknownField)
+}
+
+// ParseExpand return the comma separated book relations (e.g. "authors,tags")
+func ParseExpand(s string) ([]string, error) {
+	return parseNames(s, BookRelations, ErrUnknownRelation)
+}
+
+func parseNames(s string, known []string, errUnknown error) ([]string, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || containsName(names, name) {
+			continue
+		}
+		if !containsName(known, name) {
+			return nil, fmt.Errorf("%w '%s', available are %s", errUnknown, name, strings.Join(known, ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// IsBookField return whether the name is book field
+func IsBookField(name string) bool {
+	return containsName(BookFields, name)
+}
+
+// IsBookRelation return whether the name is book relation
+func IsBookRelation(name string) bool {
+	return containsName(BookRelations, name)
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
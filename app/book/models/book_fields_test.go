@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields(" id, Title,,title ")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "title"}, fields)
+
+	fields, err = ParseFields("")
+	require.NoError(t, err)
+	require.Empty(t, fields)
+
+	_, err = ParseFields("id,password")
+	require.True(t, errors.Is(err, ErrUnknownField))
+	require.EqualError(t, err, "unknown field 'password', available are id, title, author, isbn, published_year, publisher, language, page_count, version, deleted_at")
+}
+
+func TestParseExpand(t *testing.T) {
+	expand, err := ParseExpand("authors,tags")
+	require.NoError(t, err)
+	require.Equal(t, []string{"authors", "tags"}, expand)
+
+	_, err = ParseExpand("reviews")
+	require.True(t, errors.Is(err, ErrUnknownRelation))
+	require.EqualError(t, err, "unknown relation 'reviews', available are authors, categories, tags")
+}
+
+func TestBookCriteria_Fieldset(t *testing.T) {
+	require.True(t, BookCriteria{}.Selects("title"))
+	require.True(t, BookCriteria{}.Expands(RelationAuthors))
+
+	criteria := BookCriteria{Fields: []string{"id", "title"}, Expand: []string{RelationTags}}
+	require.True(t, criteria.Selects("title"))
+	require.False(t, criteria.Selects("author"))
+	require.True(t, criteria.Expands(RelationTags))
+	require.False(t, criteria.Expands(RelationAuthors))
+
+	require.False(t, BookCriteria{Expand: []string{}}.Expands(RelationTags))
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NaturalKey is comma separated book columns which identify the book outside the service (e.g. partner feed)
+type NaturalKey string
+
+// Natural key supported by upsert
+const (
+	NaturalKeyISBN        NaturalKey = "isbn"
+	NaturalKeyTitleAuthor NaturalKey = "title,author"
+)
+
+// Error of natural key
+var (
+	// ErrInvalidNaturalKey returned when the natural key is not supported
+	ErrInvalidNaturalKey = fmt.Errorf("natural key must be '%s' or '%s'", NaturalKeyISBN, NaturalKeyTitleAuthor)
+	// ErrMissingNaturalKey returned when the book has no value of the natural key column
+	ErrMissingNaturalKey = errors.New("missing natural key")
+)
+
+// ParseNaturalKey return the natural key; ISBN when it is empty
+func ParseNaturalKey(s string) (NaturalKey, error) {
+	switch key := NaturalKey(strings.TrimSpace(s)); key {
+	case "":
+		return NaturalKeyISBN, nil
+	case NaturalKeyISBN, NaturalKeyTitleAuthor:
+		return key, nil
+	}
+	return "", ErrInvalidNaturalKey
+}
+
+// Columns of the natural key
+func (k NaturalKey) Columns() []string {
+	return strings.Split(string(k), ",")
+}
+
+// Validate the book has value of every column of the natural key
+func (k NaturalKey) Validate(book Book) error {
+	for _, column := range k.Columns() {
+		if value, _ := book.ColumnValue(column).(string); value == "" {
+			return fmt.Errorf("%w: %s", ErrMissingNaturalKey, column)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNaturalKey(t *testing.T) {
+	testcases := []struct {
+		s   string
+		key NaturalKey
+		err error
+	}{
+		{"", NaturalKeyISBN, nil},
+		{"isbn", NaturalKeyISBN, nil},
+		{" title,author ", NaturalKeyTitleAuthor, nil},
+		{"author,title", "", ErrInvalidNaturalKey},
+		{"id", "", ErrInvalidNaturalKey},
+	}
+	for _, tt := range testcases {
+		key, err := ParseNaturalKey(tt.s)
+		require.Equal(t, tt.err, err, tt.s)
+		require.Equal(t, tt.key, key, tt.s)
+	}
+}
+
+func TestNaturalKeyValidate(t *testing.T) {
+	require.Equal(t, []string{"title", "author"}, NaturalKeyTitleAuthor.Columns())
+	require.NoError(t, NaturalKeyTitleAuthor.Validate(Book{Title: "some-title", Author: "some-author"}))
+	require.NoError(t, NaturalKeyISBN.Validate(Book{ISBN: "978-0-306-40615-7"}))
+	err := NaturalKeyISBN.Validate(Book{Title: "some-title", Author: "some-author"})
+	require.True(t, errors.Is(err, ErrMissingNaturalKey))
+	require.EqualError(t, err, "missing natural key: isbn")
+}
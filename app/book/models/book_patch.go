@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+)
+
+// Media type of supported patch document
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// ErrUnsupportedPatch returned when patch document is neither JSON Merge Patch nor JSON Patch
+var ErrUnsupportedPatch = errors.New("unsupported patch media type")
+
+// patchableColumns is the columns which can be changed by client
+var patchableColumns = []string{"title", "author", "isbn", "published_year", "publisher", "language", "page_count"}
+
+// Patch return new book after applied with JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document
+func (b *Book) Patch(mediaType string, doc []byte) (*Book, error) {
+	original, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var modified []byte
+	switch mediaType {
+	case MIMEMergePatch:
+		modified, err = jsonpatch.MergePatch(original, doc)
+	case MIMEJSONPatch:
+		var patch jsonpatch.Patch
+		if patch, err = jsonpatch.DecodePatch(doc); err == nil {
+			modified, err = patch.Apply(original)
+		}
+	default:
+		return nil, ErrUnsupportedPatch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: unmarshal to empty book so removed field is cleared
+	var patched Book
+	if err = json.Unmarshal(modified, &patched); err != nil {
+		return nil, err
+	}
+	patched.ID = b.ID
+	patched.Version = b.Version
+	patched.UpdatedAt = b.UpdatedAt
+	patched.CreatedAt = b.CreatedAt
+	patched.DeletedAt = b.DeletedAt
+
+	// NOTE: authors and categories is linked on create or update only
+	patched.AuthorIDs = nil
+	patched.Authors = b.Authors
+	patched.CategoryIDs = nil
+	patched.Categories = b.Categories
+	patched.Tags = b.Tags
+
+	return &patched, nil
+}
+
+// ChangedColumns return columns which have different value with other book
+func (b *Book) ChangedColumns(other *Book) (columns []string) {
+	for _, column := range patchableColumns {
+		if !reflect.DeepEqual(b.ColumnValue(column), other.ColumnValue(column)) {
+			columns = append(columns, column)
+		}
+	}
+	return
+}
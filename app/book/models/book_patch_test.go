@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBook_Patch(t *testing.T) {
+	book := &Book{ID: 7, Title: "some-title", Author: "some-author"}
+
+	testcases := []struct {
+		mediaType string
+		doc       string
+		expected  *Book
+		err       string
+	}{
+		{
+			mediaType: MIMEMergePatch,
+			doc:       `{"title":"new-title","id":99}`,
+			expected:  &Book{ID: 7, Title: "new-title", Author: "some-author"},
+		},
+		{
+			mediaType: MIMEMergePatch,
+			doc:       `{"author":null}`,
+			expected:  &Book{ID: 7, Title: "some-title"},
+		},
+		{
+			mediaType: MIMEJSONPatch,
+			doc:       `[{"op":"replace","path":"/author","value":"new-author"}]`,
+			expected:  &Book{ID: 7, Title: "some-title", Author: "new-author"},
+		},
+		{
+			mediaType: MIMEJSONPatch,
+			doc:       `[{"op":"test","path":"/title","value":"other-title"}]`,
+			err:       "testing value /title failed: test failed",
+		},
+		{
+			mediaType: "application/json",
+			doc:       `{}`,
+			err:       "unsupported patch media type",
+		},
+	}
+
+	for _, tt := range testcases {
+		patched, err := book.Patch(tt.mediaType, []byte(tt.doc))
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, patched)
+	}
+}
+
+func TestBook_ChangedColumns(t *testing.T) {
+	book := &Book{ID: 7, Title: "some-title", Author: "some-author"}
+
+	require.Empty(t, book.ChangedColumns(&Book{ID: 7, Title: "some-title", Author: "some-author"}))
+	require.Equal(t, []string{"author"}, book.ChangedColumns(&Book{ID: 7, Title: "some-title", Author: "new-author"}))
+}
@@ -0,0 +1,14 @@
+package models
+
+// BookSearchResult is book matched with full-text search query
+type BookSearchResult struct {
+	Book
+	Rank       float64        `json:"rank"`
+	Highlights BookHighlights `json:"highlights"`
+}
+
+// BookHighlights is snippet of matched text where the matched terms wrapped with <mark> tag
+type BookHighlights struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
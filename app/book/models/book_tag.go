@@ -0,0 +1,29 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxTagLength is maximum number of characters in a tag
+const MaxTagLength = 64
+
+// ErrInvalidTag returned when tag is empty or too long
+var ErrInvalidTag = errors.New("invalid tag")
+
+// NormalizeTags return lower-cased and trimmed tags without duplicate
+func NormalizeTags(tags []string) (normalized []string, err error) {
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len([]rune(tag)) > MaxTagLength {
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidTag, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("normalized", func(t *testing.T) {
+		tags, err := NormalizeTags([]string{" Fantasy", "classic ", "fantasy"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"fantasy", "classic"}, tags)
+	})
+
+	t.Run("empty tag", func(t *testing.T) {
+		_, err := NormalizeTags([]string{"classic", "  "})
+		require.True(t, errors.Is(err, ErrInvalidTag))
+	})
+
+	t.Run("too long tag", func(t *testing.T) {
+		_, err := NormalizeTags([]string{strings.Repeat("a", MaxTagLength+1)})
+		require.True(t, errors.Is(err, ErrInvalidTag))
+	})
+}
@@ -9,6 +9,9 @@ import (
 func TestBook_Validate(t *testing.T) {
 	book := Book{}
 	err := book.Validate()
-	require.EqualError(t, err, `Key: 'Book.Title' Error:Field validation for 'Title' failed on the 'required' tag
-Key: 'Book.Author' Error:Field validation for 'Author' failed on the 'required' tag`)
+	require.EqualError(t, err, `Key: 'Book.title' Error:Field validation for 'title' failed on the 'required' tag
+Key: 'Book.author' Error:Field validation for 'author' failed on the 'author_required' tag`)
+
+	book = Book{Title: "some-title", AuthorIDs: []int64{1}}
+	require.NoError(t, book.Validate())
 }
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor returned when cursor is malformed or not match with the sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// keysetColumns decode cursor value of the column which keyset pagination can sort by; only not null column is sortable since row
+// comparison with null is never true so the books after it is skipped. Value of id column is the cursor ID
+var keysetColumns = map[string]func(json.RawMessage) (interface{}, error){
+	"id":         nil,
+	"title":      decodeString,
+	"isbn":       decodeString,
+	"version":    decodeInt,
+	"updated_at": decodeTime,
+	"created_at": decodeTime,
+}
+
+// Cursor point to last book of previous page in keyset pagination
+type Cursor struct {
+	Column string      `json:"c"`
+	Desc   bool        `json:"d,omitempty"`
+	Value  interface{} `json:"v,omitempty"`
+	ID     int64       `json:"i"`
+}
+
+// KeysetColumn return true if keyset pagination can sort by the column
+func KeysetColumn(column string) bool {
+	_, ok := keysetColumns[column]
+	return ok
+}
+
+// NewCursor return cursor pointing to the book
+func NewCursor(column string, desc bool, book *Book) *Cursor {
+	cursor := &Cursor{Column: column, Desc: desc, ID: book.ID}
+	if column != "id" {
+		cursor.Value = book.ColumnValue(column)
+	}
+	return cursor
+}
+
+// DecodeCursor parse opaque cursor string
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// NOTE: value is decoded as the type of its column so it is compared exactly; otherwise timestamp is string and number is float64
+	var encoded struct {
+		Column string          `json:"c"`
+		Desc   bool            `json:"d"`
+		Value  json.RawMessage `json:"v"`
+		ID     int64           `json:"i"`
+	}
+	if err = json.Unmarshal(b, &encoded); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	decode, ok := keysetColumns[encoded.Column]
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{Column: encoded.Column, Desc: encoded.Desc, ID: encoded.ID}
+	if decode != nil {
+		if cursor.Value, err = decode(encoded.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return cursor, nil
+}
+
+// Encode cursor to opaque string
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Sort return the sort expression which the cursor created from
+func (c *Cursor) Sort() string {
+	if c.Desc {
+		return "-" + c.Column
+	}
+	return c.Column
+}
+
+func decodeString(raw json.RawMessage) (interface{}, error) {
+	var v string
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
+
+func decodeInt(raw json.RawMessage) (interface{}, error) {
+	var v int64
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
+
+func decodeTime(raw json.RawMessage) (interface{}, error) {
+	var v time.Time
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	book := &Book{ID: 12, Title: "some-title", Author: "some-author"}
+
+	cursor, err := DecodeCursor(NewCursor("title", true, book).Encode())
+	require.NoError(t, err)
+	require.Equal(t, &Cursor{Column: "title", Desc: true, Value: "some-title", ID: 12}, cursor)
+	require.Equal(t, "-title", cursor.Sort())
+
+	cursor, err = DecodeCursor(NewCursor("id", false, book).Encode())
+	require.NoError(t, err)
+	require.Equal(t, &Cursor{Column: "id", ID: 12}, cursor)
+	require.Equal(t, "id", cursor.Sort())
+}
+
+func TestCursor_TypedValue(t *testing.T) {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	book := &Book{ID: 12, Version: 9007199254740993, CreatedAt: createdAt}
+
+	cursor, err := DecodeCursor(NewCursor("version", false, book).Encode())
+	require.NoError(t, err)
+	require.Equal(t, int64(9007199254740993), cursor.Value)
+
+	cursor, err = DecodeCursor(NewCursor("created_at", true, book).Encode())
+	require.NoError(t, err)
+	require.Equal(t, createdAt, cursor.Value)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	for _, s := range []string{
+		"not-base64!",
+		"bm90LWpzb24",
+		"e30",
+		encode(`{"c":"deleted_at","i":12}`),
+		encode(`{"c":"title","v":12,"i":12}`),
+		encode(`{"c":"created_at","v":"yesterday","i":12}`),
+	} {
+		_, err := DecodeCursor(s)
+		require.Equal(t, ErrInvalidCursor, err)
+	}
+}
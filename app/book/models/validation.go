@@ -0,0 +1,81 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	isbnCleaner      = strings.NewReplacer("-", "", " ", "")
+	languageCodeRule = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+)
+
+func init() {
+	validkit.RegisterValidation("isbn_checksum", func(fl validator.FieldLevel) bool {
+		return ValidISBN(fl.Field().String())
+	}, map[string]string{
+		"en": "{0} must be a valid ISBN-10 or ISBN-13",
+		"id": "{0} harus berupa ISBN-10 atau ISBN-13 yang valid",
+	})
+	validkit.RegisterValidation("not_future_year", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int() <= int64(time.Now().Year())
+	}, map[string]string{
+		"en": "{0} must not be in the future",
+		"id": "{0} tidak boleh melebihi tahun ini",
+	})
+	validkit.RegisterValidation("author_required", func(fl validator.FieldLevel) bool {
+		book, ok := fl.Parent().Interface().(*Book)
+		return strings.TrimSpace(fl.Field().String()) != "" || (ok && len(book.AuthorIDs) > 0)
+	}, map[string]string{
+		"en": "{0} is required when author_ids is not given",
+		"id": "{0} wajib diisi jika author_ids tidak diberikan",
+	})
+	validkit.RegisterValidation("language_code", func(fl validator.FieldLevel) bool {
+		return languageCodeRule.MatchString(fl.Field().String())
+	}, map[string]string{
+		"en": "{0} must be a language code (e.g. en or pt-BR)",
+		"id": "{0} harus berupa kode bahasa (contoh: en atau pt-BR)",
+	})
+}
+
+// NormalizeISBN remove hyphen and space from ISBN
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(isbnCleaner.Replace(isbn))
+}
+
+// ValidISBN return whether the ISBN-10 or ISBN-13 has valid checksum
+func ValidISBN(isbn string) bool {
+	isbn = NormalizeISBN(isbn)
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, c := range isbn {
+			digit := int(c - '0')
+			if c == 'X' && i == 9 {
+				digit = 10
+			} else if c < '0' || c > '9' {
+				return false
+			}
+			sum += (10 - i) * digit
+		}
+		return sum%11 == 0
+	case 13:
+		sum := 0
+		for i, c := range isbn {
+			if c < '0' || c > '9' {
+				return false
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += weight * int(c-'0')
+		}
+		return sum%10 == 0
+	}
+	return false
+}
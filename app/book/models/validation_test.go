@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidISBN(t *testing.T) {
+	testcases := []struct {
+		isbn  string
+		valid bool
+	}{
+		{"0-306-40615-2", true},
+		{"0306406153", false},
+		{"080442957X", true},
+		{"978-0-306-40615-7", true},
+		{"9780306406158", false},
+		{"97803064061A7", false},
+		{"12345", false},
+	}
+
+	for _, tt := range testcases {
+		require.Equal(t, tt.valid, ValidISBN(tt.isbn), tt.isbn)
+	}
+}
+
+func TestBook_ValidateDetail(t *testing.T) {
+	book := Book{
+		Title:         "some-title",
+		Author:        "some-author",
+		ISBN:          "978-0-306-40615-8",
+		PublishedYear: 3000,
+		Language:      "English",
+		PageCount:     -1,
+	}
+	err := book.Validate()
+	require.EqualError(t, err, `Key: 'Book.isbn' Error:Field validation for 'isbn' failed on the 'isbn_checksum' tag
+Key: 'Book.published_year' Error:Field validation for 'published_year' failed on the 'not_future_year' tag
+Key: 'Book.language' Error:Field validation for 'language' failed on the 'language_code' tag
+Key: 'Book.page_count' Error:Field validation for 'page_count' failed on the 'min' tag`)
+
+	book = Book{Title: "some-title", Author: "some-author", ISBN: "978-0-306-40615-7", PublishedYear: 1999, Language: "id"}
+	require.NoError(t, book.Validate())
+}
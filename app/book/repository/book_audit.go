@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/rs/zerolog"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
+)
+
+// auditQuery wrap the book mutation so the changed books is recorded into audit table within the same statement
+type auditQuery struct {
+	ctx    context.Context
+	action string
+	// id of the changed book which value before the change is recorded; not required on insert and purge
+	id       int64
+	mutation sq.Sqlizer
+}
+
+func (q auditQuery) ToSql() (query string, args []interface{}, err error) {
+	mutation, args, err := q.mutation.ToSql()
+	if err != nil {
+		return
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s)", bookAuditTable, strings.Join(BookAuditColumns[1:7], ","))
+	switch q.action {
+	case models.AuditInsert:
+		query = fmt.Sprintf("WITH new_book AS (%s RETURNING *) %s SELECT id, ?, NULL, %s, ?, ? FROM new_book ORDER BY id RETURNING %s",
+			mutation, insert, auditValue("new_book"), bookIDColumn)
+	case models.AuditPurge:
+		query = fmt.Sprintf("WITH old_book AS (%s RETURNING *) %s SELECT id, ?, %s, NULL, ?, ? FROM old_book",
+			mutation, insert, auditValue("old_book"))
+	default:
+		// NOTE: all part of the statement see the same snapshot so old_book is the value before the change
+		query = fmt.Sprintf("WITH old_book AS (SELECT * FROM %s WHERE id = ? FOR UPDATE), new_book AS (%s RETURNING *) "+
+			"%s SELECT new_book.id, ?, %s, %s, ?, ? FROM new_book JOIN old_book ON old_book.id = new_book.id",
+			bookTable, mutation, insert, auditValue("old_book"), auditValue("new_book"))
+		args = append([]interface{}{q.id}, args...)
+	}
+	args = append(args, q.action, ctxkit.Actor(q.ctx), ctxkit.RequestID(q.ctx))
+
+	// NOTE: every book mutation is built here so it is logged once with the request logger; the arguments is not logged
+	zerolog.Ctx(q.ctx).Debug().Str("action", q.action).Int64("book_id", q.id).Str("sql", mutation).Msg("mutate book")
+
+	query, err = sq.Dollar.ReplacePlaceholders(query)
+	return
+}
+
+// auditValue return json of the book row without internal column
+func auditValue(table string) string {
+	return fmt.Sprintf("to_jsonb(%s) - '%s'", table, bookSearchColumn)
+}
+
+//History func
+func (r *InitBookRepository) History(ctx context.Context, bookID int64, criteria models.BookCriteria) (list []*models.BookAudit, total int64, err error) {
+	list = make([]*models.BookAudit, 0)
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(append(BookAuditColumns, "COUNT(*) OVER ()")...).
+		From(bookAuditTable).
+		Where(sq.Eq{bookIDColumn: bookID}).
+		OrderBy(idColumn + " DESC")
+	if criteria.Size > 0 {
+		builder = builder.Limit(criteria.Size).Offset(criteria.Offset())
+	}
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return list, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var audit models.BookAudit
+		if err = rows.Scan(append(audit.Fields(), &total)...); err != nil {
+			return
+		}
+		list = append(list, &audit)
+	}
+
+	return list, total, rows.Err()
+}
+
+//FindRevision func
+func (r *InitBookRepository) FindRevision(ctx context.Context, bookID int64, revision int64) (audit *models.BookAudit, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(BookAuditColumns...).
+		From(bookAuditTable).
+		Where(sq.Eq{idColumn: revision, bookIDColumn: bookID})
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return audit, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		audit, err = models.ScanBookAudit(rows)
+	}
+
+	return audit, err
+}
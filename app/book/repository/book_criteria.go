@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/helper/strkit"
+)
+
+// bookFilter return where condition of the criteria; soft deleted books only included in trash
+func bookFilter(criteria models.BookCriteria) sq.And {
+	filter := sq.And{}
+	if criteria.Trashed {
+		filter = append(filter, sq.NotEq{deletedAtColumn: nil})
+	} else {
+		filter = append(filter, sq.Eq{deletedAtColumn: nil})
+	}
+	if criteria.Title != "" {
+		filter = append(filter, sq.Eq{bookTitleColumn: criteria.Title})
+	}
+	if criteria.TitleLike != "" {
+		filter = append(filter, ilike(bookTitleColumn, criteria.TitleLike))
+	}
+	if criteria.Author != "" {
+		filter = append(filter, sq.Eq{bookAuthorColumn: criteria.Author})
+	}
+	if criteria.AuthorLike != "" {
+		filter = append(filter, ilike(bookAuthorColumn, criteria.AuthorLike))
+	}
+	if criteria.AuthorID > 0 {
+		filter = append(filter, sq.Expr(fmt.Sprintf("%s IN (SELECT book_id FROM book_authors WHERE author_id = ?)", idColumn), criteria.AuthorID))
+	}
+	for _, tag := range criteria.Tags {
+		filter = append(filter, sq.Expr(fmt.Sprintf("%s IN (SELECT book_id FROM %s WHERE tag = ?)", idColumn, bookTagTable), tag))
+	}
+	if criteria.CategoryID > 0 {
+		filter = append(filter, sq.Expr(fmt.Sprintf("%s IN (%s)", idColumn, categoryBooksQuery), criteria.CategoryID))
+	}
+	return filter
+}
+
+// bookOrderBy return order by clauses of the sort; id is always used as tie breaker so paging is stable
+func bookOrderBy(sort string) (orderBys []string, err error) {
+	sortByID := false
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+
+		if !isBookColumn(field) {
+			return nil, fmt.Errorf("%w: '%s'", models.ErrInvalidSort, field)
+		}
+		if field == idColumn {
+			sortByID = true
+		}
+		orderBys = append(orderBys, fmt.Sprintf("%s %s", field, direction))
+	}
+
+	if !sortByID {
+		orderBys = append(orderBys, fmt.Sprintf("%s ASC", idColumn))
+	}
+	return
+}
+
+func isBookColumn(name string) bool {
+	for _, column := range BookColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bookSelectColumns return columns of the selected fields in order of book columns; id and keyset sort column are always selected
+func bookSelectColumns(criteria models.BookCriteria) []string {
+	if len(criteria.Fields) == 0 {
+		return BookColumns
+	}
+
+	sortColumn := ""
+	if criteria.Keyset {
+		sortColumn, _ = criteria.KeysetSort()
+	}
+
+	var columns []string
+	for _, column := range BookColumns {
+		if column == idColumn || column == sortColumn || criteria.Selects(column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// bookFields return pointer of book fields as scan destination in the same order with the columns
+func bookFields(book *models.Book, columns []string) []interface{} {
+	all := book.Fields()
+	fields := make([]interface{}, len(columns))
+	for i, column := range columns {
+		for j := range BookColumns {
+			if BookColumns[j] == column {
+				fields[i] = all[j]
+				break
+			}
+		}
+	}
+	return fields
+}
+
+func ilike(column, value string) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf("%s ILIKE ?", column), "%"+strkit.EscapeLike(value)+"%")
+}
+
+// bookKeyset return order by clauses and where condition of keyset pagination which only sort by single column and id
+func bookKeyset(criteria models.BookCriteria) (orderBys []string, after sq.Sqlizer, err error) {
+	column, desc := criteria.KeysetSort()
+	if strings.Contains(column, ",") {
+		return nil, nil, fmt.Errorf("%w: keyset pagination only sort by single column", models.ErrInvalidSort)
+	}
+	// NOTE: keyset pagination only sort by not null column
+	if !models.KeysetColumn(column) {
+		return nil, nil, fmt.Errorf("%w: '%s'", models.ErrInvalidSort, column)
+	}
+
+	direction, operator := "ASC", ">"
+	if desc {
+		direction, operator = "DESC", "<"
+	}
+
+	orderBys = append(orderBys, fmt.Sprintf("%s %s", column, direction))
+	if column != idColumn {
+		orderBys = append(orderBys, fmt.Sprintf("%s %s", idColumn, direction))
+	}
+
+	if cursor := criteria.After; cursor != nil {
+		if cursor.Column != column || cursor.Desc != desc {
+			return nil, nil, models.ErrInvalidCursor
+		}
+		if column == idColumn {
+			after = sq.Expr(fmt.Sprintf("%s %s ?", idColumn, operator), cursor.ID)
+		} else {
+			after = sq.Expr(fmt.Sprintf("(%s, %s) %s (?, ?)", column, idColumn, operator), cursor.Value, cursor.ID)
+		}
+	}
+	return
+}
@@ -3,20 +3,39 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 	"github.com/typical-go/typical-rest-server/app/book/models"
 	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
 )
 
 // BookRepository to get book data from databasesa
 type BookRepository interface {
-	Find(id int64) (*models.Book, error)
-	List() ([]*models.Book, error)
+	Find(ctx context.Context, id int64) (*models.Book, error)
+	FindByISBN(ctx context.Context, isbn string) (*models.Book, error)
+	List(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, error)
+	Count(ctx context.Context, criteria models.BookCriteria) (int64, error)
+	Search(ctx context.Context, query string, criteria models.BookCriteria) ([]*models.BookSearchResult, int64, error)
+	Stream(ctx context.Context, criteria models.BookCriteria, fn func(*models.Book) error) error
 	Insert(ctx context.Context, book models.Book) (lastInsertID int64, err error)
+	InsertBatch(ctx context.Context, books []models.Book) (ids []int64, err error)
 	Update(ctx context.Context, book models.Book) error
-	Delete(ctx context.Context, id int64) error
+	Patch(ctx context.Context, book models.Book, columns []string) error
+	Delete(ctx context.Context, id int64, version int64) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ListTags(ctx context.Context, bookIDs []int64) (map[int64][]string, error)
+	AddTags(ctx context.Context, bookID int64, tags []string) error
+	RemoveTags(ctx context.Context, bookID int64, tags []string) error
+	History(ctx context.Context, bookID int64, criteria models.BookCriteria) ([]*models.BookAudit, int64, error)
+	FindRevision(ctx context.Context, bookID int64, revision int64) (*models.BookAudit, error)
+	Revert(ctx context.Context, book models.Book) error
+	Upsert(ctx context.Context, key models.NaturalKey, book models.Book) (int64, bool, error)
 }
 
 //InitBookRepository struct
@@ -32,100 +51,548 @@ func NewBookRepository(conn *sql.DB) BookRepository {
 }
 
 //Find func
-func (r *InitBookRepository) Find(id int64) (book *models.Book, err error) {
+func (r *InitBookRepository) Find(ctx context.Context, id int64) (book *models.Book, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	builder := psql.Select(BookColumns...).
 		From(bookTable).
-		Where(sq.Eq{idColumn: id})
+		Where(sq.Eq{idColumn: id, deletedAtColumn: nil})
 
-	rows, err := builder.RunWith(r.conn).Query()
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
 	if err != nil {
 		return book, err
 	}
+	defer rows.Close()
 
 	if rows.Next() {
-		book, err = models.ScanBook(rows)
+		if book, err = models.ScanBook(rows); err != nil {
+			return nil, err
+		}
 	}
 
-	return book, err
+	return book, rows.Err()
 }
 
-//List func
-func (r *InitBookRepository) List() (list []*models.Book, err error) {
+//FindByISBN func
+func (r *InitBookRepository) FindByISBN(ctx context.Context, isbn string) (book *models.Book, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	builder := psql.Select(BookColumns...).From(bookTable).OrderBy("id ASC")
+	builder := psql.Select(BookColumns...).
+		From(bookTable).
+		Where(sq.Eq{bookISBNColumn: models.NormalizeISBN(isbn), deletedAtColumn: nil})
 
-	rows, err := builder.RunWith(r.conn).Query()
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return book, err
+	}
+	defer rows.Close()
 
+	if rows.Next() {
+		if book, err = models.ScanBook(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return book, rows.Err()
+}
+
+//List func
+func (r *InitBookRepository) List(ctx context.Context, criteria models.BookCriteria) (list []*models.Book, err error) {
 	list = make([]*models.Book, 0)
+	filter := bookFilter(criteria)
 
+	var orderBys []string
+	if criteria.Keyset {
+		var after sq.Sqlizer
+		if orderBys, after, err = bookKeyset(criteria); err != nil {
+			return list, err
+		}
+		if after != nil {
+			filter = append(filter, after)
+		}
+	} else if orderBys, err = bookOrderBy(criteria.Sort); err != nil {
+		return list, err
+	}
+
+	columns := bookSelectColumns(criteria)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(columns...).From(bookTable).Where(filter).OrderBy(orderBys...)
+	if criteria.Size > 0 {
+		builder = builder.Limit(criteria.Size)
+		if !criteria.Keyset {
+			builder = builder.Offset(criteria.Offset())
+		}
+	}
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
 	if err != nil {
 		return list, err
 	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book models.Book
+		if err = rows.Scan(bookFields(&book, columns)...); err != nil {
+			return
+		}
+		list = append(list, &book)
+	}
+
+	return list, rows.Err()
+}
+
+//Count func
+func (r *InitBookRepository) Count(ctx context.Context, criteria models.BookCriteria) (total int64, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select("COUNT(*)").From(bookTable).Where(bookFilter(criteria))
+
+	err = builder.RunWith(r.conn).QueryRowContext(ctx).Scan(&total)
+	return total, err
+}
+
+//Stream func
+func (r *InitBookRepository) Stream(ctx context.Context, criteria models.BookCriteria, fn func(*models.Book) error) (err error) {
+	orderBys, err := bookOrderBy(criteria.Sort)
+	if err != nil {
+		return err
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(BookColumns...).From(bookTable).Where(bookFilter(criteria)).OrderBy(orderBys...)
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
+	// NOTE: scan one book at a time so the whole table is never loaded into memory
+	var book models.Book
 	for rows.Next() {
-		var book *models.Book
-		book, err = models.ScanBook(rows)
+		if err = rows.Scan(book.Fields()...); err != nil {
+			return err
+		}
+		if err = fn(&book); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+//Search func
+func (r *InitBookRepository) Search(ctx context.Context, query string, criteria models.BookCriteria) (list []*models.BookSearchResult, total int64, err error) {
+	list = make([]*models.BookSearchResult, 0)
+
+	filter := append(bookFilter(criteria), sq.Expr(fmt.Sprintf("%s @@ query", bookSearchColumn)))
+
+	columns := bookSelectColumns(criteria)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(columns...).
+		Column(fmt.Sprintf("ts_rank(%s, query) AS rank", bookSearchColumn)).
+		Column(fmt.Sprintf("ts_headline('%s', %s, query, '%s')", searchConfig, bookTitleColumn, searchHeadline)).
+		Column(fmt.Sprintf("ts_headline('%s', %s, query, '%s')", searchConfig, bookAuthorColumn, searchHeadline)).
+		Column("COUNT(*) OVER ()").
+		From(bookTable).
+		JoinClause(fmt.Sprintf("CROSS JOIN websearch_to_tsquery('%s', ?) AS query", searchConfig), query).
+		Where(filter).
+		OrderBy("rank DESC", fmt.Sprintf("%s ASC", idColumn))
+	if criteria.Size > 0 {
+		builder = builder.Limit(criteria.Size).Offset(criteria.Offset())
+	}
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return list, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.BookSearchResult
+		err = rows.Scan(append(bookFields(&result.Book, columns),
+			&result.Rank, &result.Highlights.Title, &result.Highlights.Author, &total)...)
 		if err != nil {
 			return
 		}
-		list = append(list, book)
+		list = append(list, &result)
 	}
 
-	return list, err
+	return list, total, rows.Err()
 }
 
 //Insert func
 func (r *InitBookRepository) Insert(ctx context.Context, book models.Book) (lastInsertID int64, err error) {
+	ids, err := r.insert(ctx, []models.Book{book})
+	if err != nil {
+		return lastInsertID, err
+	}
+
+	lastInsertID = ids[0]
+	return lastInsertID, err
+}
+
+//InsertBatch func
+func (r *InitBookRepository) InsertBatch(ctx context.Context, books []models.Book) (ids []int64, err error) {
+	if len(books) == 0 {
+		return nil, nil
+	}
+
+	return r.insert(ctx, books)
+}
+
+func (r *InitBookRepository) insert(ctx context.Context, books []models.Book) (ids []int64, err error) {
 	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return nil, err
+	}
 
-	query := sq.Insert(bookTable).
-		Columns(bookTitleColumn, bookAuthorColumn).
-		Values(book.Title, book.Author).
-		Suffix("RETURNING \"id\"").
-		RunWith(trxn.DB).
-		PlaceholderFormat(sq.Dollar)
+	builder := sq.Insert(bookTable).
+		Columns(bookValueColumns...)
+	for _, book := range books {
+		builder = builder.Values(bookValues(book)...)
+	}
 
-	err = query.QueryRow().Scan(&book.ID)
+	rows, err := sq.QueryContextWith(ctx, trxn.DB, auditQuery{ctx: ctx, action: models.AuditInsert, mutation: builder})
 	if err != nil {
+		err = bookError(err)
 		trxn.SetError(err)
-		return lastInsertID, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	lastInsertID = book.ID
-	return lastInsertID, err
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			trxn.SetError(err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = bookError(rows.Err()); err != nil {
+		trxn.SetError(err)
+		return nil, err
+	}
+
+	return ids, err
+}
+
+//Upsert func
+func (r *InitBookRepository) Upsert(ctx context.Context, key models.NaturalKey, book models.Book) (id int64, created bool, err error) {
+	if !naturalKeys[key] {
+		return 0, false, models.ErrInvalidNaturalKey
+	}
+
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return 0, false, err
+	}
+
+	match := sq.Eq{deletedAtColumn: nil}
+	lockKey := []string{bookTable, string(key)}
+	for _, column := range key.Columns() {
+		match[column] = book.ColumnValue(column)
+		lockKey = append(lockKey, fmt.Sprint(book.ColumnValue(column)))
+	}
+
+	// NOTE: the natural key is not backed by unique index (except isbn) so book with the same key is upserted one at a time
+	if _, err = trxn.DB.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", strings.Join(lockKey, "\x00")); err != nil {
+		trxn.SetError(err)
+		return 0, false, err
+	}
+
+	id, err = findBookID(ctx, trxn.DB, match)
+	if errors.Is(err, models.ErrBookNotFound) {
+		if id, err = r.Insert(ctx, book); err != nil {
+			return 0, false, err
+		}
+		return id, true, nil
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return 0, false, err
+	}
+
+	// NOTE: the book is not updated when nothing is changed so its version is kept
+	builder := sq.Update(bookTable)
+	for _, column := range bookValueColumns {
+		builder = builder.Set(column, book.ColumnValue(column))
+	}
+	builder = builder.Set(updatedAtColumn, time.Now()).
+		Set(versionColumn, sq.Expr(versionColumn+" + 1")).
+		Where(sq.Eq{idColumn: id}).
+		Where(sq.Expr(fmt.Sprintf("(%s) IS DISTINCT FROM (%s)", strings.Join(bookValueColumns, ", "), sq.Placeholders(len(bookValueColumns))),
+			bookValues(book)...))
+
+	_, err = sq.ExecContextWith(ctx, trxn.DB, auditQuery{ctx: ctx, action: models.AuditUpdate, id: id, mutation: builder})
+	if err = bookError(err); err != nil {
+		trxn.SetError(err)
+		return 0, false, err
+	}
+
+	return id, false, nil
+}
+
+// findBookID return id of the book which match the condition
+func findBookID(ctx context.Context, db dbtrxn.Runner, where sq.Sqlizer) (id int64, err error) {
+	rows, err := sq.Select(idColumn).
+		From(bookTable).
+		Where(where).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(db).
+		QueryContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err == nil {
+			err = models.ErrBookNotFound
+		}
+		return 0, err
+	}
+	err = rows.Scan(&id)
+	return id, err
 }
 
 //Update func
 func (r *InitBookRepository) Update(ctx context.Context, book models.Book) (err error) {
+	return r.update(ctx, models.AuditUpdate, book, bookValueColumns)
+}
+
+//Patch func
+func (r *InitBookRepository) Patch(ctx context.Context, book models.Book, columns []string) (err error) {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	return r.update(ctx, models.AuditPatch, book, columns)
+}
+
+//Revert func
+func (r *InitBookRepository) Revert(ctx context.Context, book models.Book) (err error) {
+	return r.update(ctx, models.AuditRevert, book, bookValueColumns)
+}
+
+func (r *InitBookRepository) update(ctx context.Context, action string, book models.Book, columns []string) (err error) {
 	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
 
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	builder := psql.Update(bookTable).
-		Set(bookTitleColumn, book.Title).
-		Set(bookAuthorColumn, book.Author).
+	builder := sq.Update(bookTable)
+	for _, column := range columns {
+		builder = builder.Set(column, book.ColumnValue(column))
+	}
+	builder = builder.Set(updatedAtColumn, time.Now()).
+		Set(versionColumn, sq.Expr(versionColumn+" + 1")).
+		Where(versionCondition(book.ID, book.Version))
+
+	result, err := sq.ExecContextWith(ctx, trxn.DB, auditQuery{ctx: ctx, action: action, id: book.ID, mutation: builder})
+	if err = bookError(err); err == nil {
+		err = checkVersion(ctx, trxn.DB, result, book.ID, book.Version)
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//Delete func
+func (r *InitBookRepository) Delete(ctx context.Context, id int64, version int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: soft delete; the book is permanently removed by purge after retention period
+	builder := sq.Update(bookTable).
+		Set(deletedAtColumn, time.Now()).
+		Set(versionColumn, sq.Expr(versionColumn+" + 1")).
+		Where(versionCondition(id, version))
+
+	result, err := sq.ExecContextWith(ctx, trxn.DB, auditQuery{ctx: ctx, action: models.AuditDelete, id: id, mutation: builder})
+	if err == nil {
+		err = checkVersion(ctx, trxn.DB, result, id, version)
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//Restore func
+func (r *InitBookRepository) Restore(ctx context.Context, id int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	builder := sq.Update(bookTable).
+		Set(deletedAtColumn, nil).
 		Set(updatedAtColumn, time.Now()).
-		Where(sq.Eq{idColumn: book.ID})
+		Set(versionColumn, sq.Expr(versionColumn+" + 1")).
+		Where(sq.And{sq.Eq{idColumn: id}, sq.NotEq{deletedAtColumn: nil}})
+
+	result, err := sq.ExecContextWith(ctx, trxn.DB, auditQuery{ctx: ctx, action: models.AuditRestore, id: id, mutation: builder})
+	if err = bookError(err); err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected < 1 {
+			err = models.ErrBookNotFound
+		}
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//Purge func
+func (r *InitBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (purged int64, err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return purged, err
+	}
 
-	_, err = builder.RunWith(trxn.DB).Exec()
+	builder := sq.Delete(bookTable).
+		Where(sq.Lt{deletedAtColumn: deletedBefore})
 
+	result, err := sq.ExecContextWith(ctx, trxn.DB, auditQuery{ctx: ctx, action: models.AuditPurge, mutation: builder})
+	if err == nil {
+		purged, err = result.RowsAffected()
+	}
 	if err != nil {
 		trxn.SetError(err)
+		return purged, err
+	}
+
+	return purged, err
+}
+
+// versionCondition return where condition of active book and its expected version; any version is accepted when version is 0
+func versionCondition(id, version int64) sq.Eq {
+	if version > 0 {
+		return sq.Eq{idColumn: id, versionColumn: version, deletedAtColumn: nil}
+	}
+	return sq.Eq{idColumn: id, deletedAtColumn: nil}
+}
+
+// checkVersion return version conflict error when the expected version of active book is not affected or not found error when the book is missing or trashed
+func checkVersion(ctx context.Context, db dbtrxn.Runner, result sql.Result, id, version int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
 		return err
 	}
+	if affected > 0 {
+		return nil
+	}
+	if version <= 0 {
+		return models.ErrBookNotFound
+	}
+
+	// NOTE: book trashed since its version is fetched is not found rather than conflict
+	if _, err = findBookID(ctx, db, sq.Eq{idColumn: id, deletedAtColumn: nil}); err != nil {
+		return err
+	}
+	return models.ErrVersionConflict
+}
 
+// bookValues return value of book columns given by client
+func bookValues(book models.Book) (values []interface{}) {
+	for _, column := range bookValueColumns {
+		values = append(values, book.ColumnValue(column))
+	}
+	return
+}
+
+// bookError translate unique violation error into book error
+func bookError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolation {
+		return err
+	}
+	switch pqErr.Constraint {
+	case bookISBNConstraint:
+		return models.ErrDuplicateISBN
+	}
 	return err
 }
 
-//Delete func
-func (r *InitBookRepository) Delete(ctx context.Context, id int64) (err error) {
+//ListTags func
+func (r *InitBookRepository) ListTags(ctx context.Context, bookIDs []int64) (tags map[int64][]string, err error) {
+	tags = make(map[int64][]string)
+	if len(bookIDs) == 0 {
+		return tags, nil
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(bookIDColumn, bookTagColumn).
+		From(bookTagTable).
+		Where(sq.Eq{bookIDColumn: bookIDs}).
+		OrderBy(bookIDColumn, bookTagColumn)
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return tags, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookID int64
+		var tag string
+		if err = rows.Scan(&bookID, &tag); err != nil {
+			return
+		}
+		tags[bookID] = append(tags[bookID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+//AddTags func
+func (r *InitBookRepository) AddTags(ctx context.Context, bookID int64, tags []string) (err error) {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Insert(bookTagTable).
+		Columns(bookIDColumn, bookTagColumn).
+		Suffix("ON CONFLICT DO NOTHING")
+	for _, tag := range tags {
+		builder = builder.Values(bookID, tag)
+	}
+
+	_, err = builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//RemoveTags func
+func (r *InitBookRepository) RemoveTags(ctx context.Context, bookID int64, tags []string) (err error) {
 	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
 
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	builder := psql.Delete(bookTable).
-		Where(sq.Eq{idColumn: id})
+	builder := psql.Delete(bookTagTable).
+		Where(sq.Eq{bookIDColumn: bookID, bookTagColumn: tags})
 
-	_, err = builder.RunWith(trxn.DB).Exec()
+	_, err = builder.RunWith(trxn.DB).ExecContext(ctx)
 	if err != nil {
 		trxn.SetError(err)
 		return err
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"testing"
@@ -9,11 +10,33 @@ import (
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	_ "github.com/golang-migrate/migrate/source/file"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"github.com/typical-go/typical-rest-server/app/book/models"
 	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
 )
 
+// insertAuditSQL return expected statement of book insert which is recorded into audit table
+func insertAuditSQL(insert string, next int) string {
+	return regexp.QuoteMeta(fmt.Sprintf(`WITH new_book AS (%s RETURNING *) `+
+		`INSERT INTO book_audit (book_id,action,old_value,new_value,actor,request_id) `+
+		`SELECT id, $%d, NULL, to_jsonb(new_book) - 'search_vector', $%d, $%d FROM new_book ORDER BY id RETURNING book_id`,
+		insert, next, next+1, next+2))
+}
+
+// changeAuditSQL return expected statement of book change which is recorded into audit table; $1 is the changed book id
+func changeAuditSQL(change string, next int) string {
+	return regexp.QuoteMeta(fmt.Sprintf(`WITH old_book AS (SELECT * FROM books WHERE id = $1 FOR UPDATE), new_book AS (%s RETURNING *) `+
+		`INSERT INTO book_audit (book_id,action,old_value,new_value,actor,request_id) `+
+		`SELECT new_book.id, $%d, to_jsonb(old_book) - 'search_vector', to_jsonb(new_book) - 'search_vector', $%d, $%d `+
+		`FROM new_book JOIN old_book ON old_book.id = new_book.id`,
+		change, next, next+1, next+2))
+}
+
+// activeBookSQL is expected statement to check whether the book exist and not trashed
+var activeBookSQL = regexp.QuoteMeta(`SELECT id FROM books WHERE deleted_at IS NULL AND id = $1`)
+
 func TestBookRepository(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -22,70 +45,364 @@ func TestBookRepository(t *testing.T) {
 	bookRepository := repository.NewBookRepository(db)
 
 	t.Run("Insert", func(t *testing.T) {
-		insertSQL := regexp.QuoteMeta(`INSERT INTO books (title,author) VALUES ($1,$2) RETURNING "id"`)
+		insertSQL := insertAuditSQL(`INSERT INTO books (title,author,isbn,published_year,publisher,language,page_count) `+
+			`VALUES ($1,$2,$3,$4,$5,$6,$7)`, 8)
 
 		t.Run("sql error", func(t *testing.T) {
-			mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author").
+			mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author", "", 0, "", "", 0, "insert", "", "").
 				WillReturnError(fmt.Errorf("some-insert-error"))
 
 			_, err = bookRepository.Insert(context.TODO(), models.Book{Title: "some-title", Author: "some-author"})
 			require.EqualError(t, err, "some-insert-error")
 		})
 
+		t.Run("duplicate isbn", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author", "9780306406157", 0, "", "", 0, "insert", "", "").
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "books_isbn_key"})
+
+			_, err = bookRepository.Insert(context.TODO(), models.Book{Title: "some-title", Author: "some-author", ISBN: "978-0-306-40615-7"})
+			require.Equal(t, models.ErrDuplicateISBN, err)
+		})
+
 		t.Run("sql success", func(t *testing.T) {
-			mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author").
-				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(999))
+			ctx := ctxkit.WithRequestID(ctxkit.WithActor(context.TODO(), "some-actor"), "some-request-id")
+			mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author", "", 0, "", "", 0, "insert", "some-actor", "some-request-id").
+				WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(999))
 
-			id, err := bookRepository.Insert(context.TODO(), models.Book{Title: "some-title", Author: "some-author"})
+			id, err := bookRepository.Insert(ctx, models.Book{Title: "some-title", Author: "some-author"})
 			require.NoError(t, err)
 			require.Equal(t, int64(999), id)
 		})
 	})
 
+	t.Run("InsertBatch", func(t *testing.T) {
+		insertSQL := insertAuditSQL(`INSERT INTO books (title,author,isbn,published_year,publisher,language,page_count) `+
+			`VALUES ($1,$2,$3,$4,$5,$6,$7),($8,$9,$10,$11,$12,$13,$14)`, 15)
+		books := []models.Book{
+			{Title: "some-title1", Author: "some-author1"},
+			{Title: "some-title2", Author: "some-author2"},
+		}
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-title1", "some-author1", "", 0, "", "", 0, "some-title2", "some-author2", "", 0, "", "", 0, "insert", "", "").
+				WillReturnError(fmt.Errorf("some-insert-error"))
+
+			_, err = bookRepository.InsertBatch(context.TODO(), books)
+			require.EqualError(t, err, "some-insert-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-title1", "some-author1", "", 0, "", "", 0, "some-title2", "some-author2", "", 0, "", "", 0, "insert", "", "").
+				WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(11).AddRow(12))
+
+			ids, err := bookRepository.InsertBatch(context.TODO(), books)
+			require.NoError(t, err)
+			require.Equal(t, []int64{11, 12}, ids)
+		})
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		lockSQL := regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)
+		matchSQL := regexp.QuoteMeta(`SELECT id FROM books WHERE author = $1 AND deleted_at IS NULL AND title = $2`)
+		insertSQL := insertAuditSQL(`INSERT INTO books (title,author,isbn,published_year,publisher,language,page_count) VALUES ($1,$2,$3,$4,$5,$6,$7)`, 8)
+		updateSQL := changeAuditSQL(`UPDATE books SET title = $2, author = $3, isbn = $4, published_year = $5, publisher = $6, `+
+			`language = $7, page_count = $8, updated_at = $9, version = version + 1 WHERE id = $10 `+
+			`AND (title, author, isbn, published_year, publisher, language, page_count) IS DISTINCT FROM ($11,$12,$13,$14,$15,$16,$17)`, 18)
+		book := models.Book{Title: "some-title", Author: "some-author", PageCount: 100}
+
+		t.Run("invalid key", func(t *testing.T) {
+			_, _, err := bookRepository.Upsert(context.TODO(), "id", book)
+			require.Equal(t, models.ErrInvalidNaturalKey, err)
+		})
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(lockSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(matchSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(insertSQL).WillReturnError(&pq.Error{Code: "23505", Constraint: "books_isbn_key"})
+			_, _, err := bookRepository.Upsert(context.TODO(), models.NaturalKeyTitleAuthor, book)
+			require.Equal(t, models.ErrDuplicateISBN, err)
+		})
+
+		t.Run("created", func(t *testing.T) {
+			mock.ExpectExec(lockSQL).WithArgs("books\x00title,author\x00some-title\x00some-author").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(matchSQL).WithArgs("some-author", "some-title").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(insertSQL).
+				WithArgs("some-title", "some-author", "", 0, "", "", 100, "insert", "", "").
+				WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(21))
+			id, created, err := bookRepository.Upsert(context.TODO(), models.NaturalKeyTitleAuthor, book)
+			require.NoError(t, err)
+			require.Equal(t, int64(21), id)
+			require.True(t, created)
+		})
+
+		t.Run("updated", func(t *testing.T) {
+			mock.ExpectExec(lockSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(matchSQL).WithArgs("some-author", "some-title").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(21))
+			mock.ExpectExec(updateSQL).
+				WithArgs(21, "some-title", "some-author", "", 0, "", "", 100, sqlmock.AnyArg(), 21,
+					"some-title", "some-author", "", 0, "", "", 100, "update", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			id, created, err := bookRepository.Upsert(context.TODO(), models.NaturalKeyTitleAuthor, book)
+			require.NoError(t, err)
+			require.Equal(t, int64(21), id)
+			require.False(t, created)
+		})
+
+		t.Run("nothing changed", func(t *testing.T) {
+			mock.ExpectExec(lockSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(matchSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(21))
+			mock.ExpectExec(updateSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+			id, created, err := bookRepository.Upsert(context.TODO(), models.NaturalKeyTitleAuthor, book)
+			require.NoError(t, err)
+			require.Equal(t, int64(21), id)
+			require.False(t, created)
+		})
+
+		t.Run("by isbn", func(t *testing.T) {
+			mock.ExpectExec(lockSQL).WithArgs("books\x00isbn\x009780306406157").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM books WHERE deleted_at IS NULL AND isbn = $1`)).WithArgs("9780306406157").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(insertSQL).
+				WithArgs("some-title", "some-author", "9780306406157", 0, "", "", 100, "insert", "", "").
+				WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(22))
+			book := book
+			book.ISBN = "978-0-306-40615-7"
+			id, created, err := bookRepository.Upsert(context.TODO(), models.NaturalKeyISBN, book)
+			require.NoError(t, err)
+			require.Equal(t, int64(22), id)
+			require.True(t, created)
+		})
+	})
+
 	t.Run("Update", func(t *testing.T) {
-		updateSQL := regexp.QuoteMeta(`UPDATE books SET title = $1, author = $2, updated_at = $3 WHERE id = $4`)
+		updateSQL := changeAuditSQL(`UPDATE books SET title = $2, author = $3, isbn = $4, published_year = $5, publisher = $6, `+
+			`language = $7, page_count = $8, updated_at = $9, version = version + 1 WHERE deleted_at IS NULL AND id = $10`, 11)
 
 		t.Run("sql error", func(t *testing.T) {
-			mock.ExpectExec(updateSQL).WithArgs("new-title", "new-author", sqlmock.AnyArg(), 888).
+			mock.ExpectExec(updateSQL).WithArgs(888, "new-title", "new-author", "", 0, "", "", 0, sqlmock.AnyArg(), 888, "update", "", "").
 				WillReturnError(fmt.Errorf("some-update-error"))
 			err = bookRepository.Update(context.TODO(), models.Book{ID: 888, Title: "new-title", Author: "new-author"})
 			require.EqualError(t, err, "some-update-error")
 		})
 
 		t.Run("sql success", func(t *testing.T) {
-			mock.ExpectExec(updateSQL).WithArgs("new-title", "new-author", sqlmock.AnyArg(), 888).
+			mock.ExpectExec(updateSQL).WithArgs(888, "new-title", "new-author", "", 0, "", "", 0, sqlmock.AnyArg(), 888, "update", "", "").
 				WillReturnResult(sqlmock.NewResult(1, 1))
 			err = bookRepository.Update(context.TODO(), models.Book{ID: 888, Title: "new-title", Author: "new-author"})
 			require.NoError(t, err)
 		})
+
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs(777, "new-title", "new-author", "", 0, "", "", 0, sqlmock.AnyArg(), 777, "update", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			err = bookRepository.Update(context.TODO(), models.Book{ID: 777, Title: "new-title", Author: "new-author"})
+			require.Equal(t, models.ErrBookNotFound, err)
+		})
+
+		t.Run("version conflict", func(t *testing.T) {
+			mock.ExpectExec(changeAuditSQL(`UPDATE books SET title = $2, author = $3, isbn = $4, published_year = $5, publisher = $6, `+
+				`language = $7, page_count = $8, updated_at = $9, version = version + 1 WHERE deleted_at IS NULL AND id = $10 AND version = $11`, 12)).
+				WithArgs(888, "new-title", "new-author", "", 0, "", "", 0, sqlmock.AnyArg(), 888, 2, "update", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(activeBookSQL).WithArgs(888).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(888))
+			err = bookRepository.Update(context.TODO(), models.Book{ID: 888, Title: "new-title", Author: "new-author", Version: 2})
+			require.Equal(t, models.ErrVersionConflict, err)
+		})
+
+		t.Run("trashed", func(t *testing.T) {
+			mock.ExpectExec(changeAuditSQL(`UPDATE books SET title = $2, author = $3, isbn = $4, published_year = $5, publisher = $6, `+
+				`language = $7, page_count = $8, updated_at = $9, version = version + 1 WHERE deleted_at IS NULL AND id = $10 AND version = $11`, 12)).
+				WithArgs(888, "new-title", "new-author", "", 0, "", "", 0, sqlmock.AnyArg(), 888, 2, "update", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(activeBookSQL).WithArgs(888).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			err = bookRepository.Update(context.TODO(), models.Book{ID: 888, Title: "new-title", Author: "new-author", Version: 2})
+			require.Equal(t, models.ErrBookNotFound, err)
+		})
+	})
+
+	t.Run("Patch", func(t *testing.T) {
+		patchSQL := changeAuditSQL(`UPDATE books SET author = $2, updated_at = $3, version = version + 1 WHERE deleted_at IS NULL AND id = $4`, 5)
+		book := models.Book{ID: 888, Title: "new-title", Author: "new-author"}
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(patchSQL).WithArgs(888, "new-author", sqlmock.AnyArg(), 888, "patch", "", "").
+				WillReturnError(fmt.Errorf("some-patch-error"))
+			err = bookRepository.Patch(context.TODO(), book, []string{"author"})
+			require.EqualError(t, err, "some-patch-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(patchSQL).WithArgs(888, "new-author", sqlmock.AnyArg(), 888, "patch", "", "").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			err = bookRepository.Patch(context.TODO(), book, []string{"author"})
+			require.NoError(t, err)
+		})
+
+		t.Run("nothing changed", func(t *testing.T) {
+			err = bookRepository.Patch(context.TODO(), book, nil)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		mock.ExpectExec(changeAuditSQL(`UPDATE books SET title = $2, author = $3, isbn = $4, published_year = $5, publisher = $6, `+
+			`language = $7, page_count = $8, updated_at = $9, version = version + 1 WHERE deleted_at IS NULL AND id = $10 AND version = $11`, 12)).
+			WithArgs(888, "old-title", "old-author", "", 0, "", "", 0, sqlmock.AnyArg(), 888, 5, "revert", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		err = bookRepository.Revert(context.TODO(), models.Book{ID: 888, Title: "old-title", Author: "old-author", Version: 5})
+		require.NoError(t, err)
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		deleteSQL := regexp.QuoteMeta(`DELETE FROM books WHERE id = $1`)
+		deleteSQL := changeAuditSQL(`UPDATE books SET deleted_at = $2, version = version + 1 WHERE deleted_at IS NULL AND id = $3`, 4)
+		deleteVersionSQL := changeAuditSQL(`UPDATE books SET deleted_at = $2, version = version + 1 WHERE deleted_at IS NULL AND id = $3 AND version = $4`, 5)
 
 		t.Run("sql error", func(t *testing.T) {
-			mock.ExpectExec(deleteSQL).WithArgs(666).
+			mock.ExpectExec(deleteSQL).WithArgs(666, sqlmock.AnyArg(), 666, "delete", "", "").
 				WillReturnError(fmt.Errorf("some-delete-error"))
-			err := bookRepository.Delete(context.TODO(), 666)
+			err := bookRepository.Delete(context.TODO(), 666, 0)
 			require.EqualError(t, err, "some-delete-error")
 		})
 
 		t.Run("sql success", func(t *testing.T) {
-			mock.ExpectExec(deleteSQL).WithArgs(555).
+			mock.ExpectExec(deleteSQL).WithArgs(555, sqlmock.AnyArg(), 555, "delete", "", "").
 				WillReturnResult(sqlmock.NewResult(1, 1))
-			err := bookRepository.Delete(context.TODO(), 555)
+			err := bookRepository.Delete(context.TODO(), 555, 0)
+			require.NoError(t, err)
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(444, sqlmock.AnyArg(), 444, "delete", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			err := bookRepository.Delete(context.TODO(), 444, 0)
+			require.Equal(t, models.ErrBookNotFound, err)
+		})
+
+		t.Run("expected version", func(t *testing.T) {
+			mock.ExpectExec(deleteVersionSQL).WithArgs(555, sqlmock.AnyArg(), 555, 4, "delete", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			err := bookRepository.Delete(context.TODO(), 555, 4)
+			require.NoError(t, err)
+		})
+
+		t.Run("version conflict", func(t *testing.T) {
+			mock.ExpectExec(deleteVersionSQL).WithArgs(555, sqlmock.AnyArg(), 555, 4, "delete", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(activeBookSQL).WithArgs(555).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(555))
+			err := bookRepository.Delete(context.TODO(), 555, 4)
+			require.Equal(t, models.ErrVersionConflict, err)
+		})
+
+		t.Run("trashed", func(t *testing.T) {
+			mock.ExpectExec(deleteVersionSQL).WithArgs(555, sqlmock.AnyArg(), 555, 4, "delete", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(activeBookSQL).WithArgs(555).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			err := bookRepository.Delete(context.TODO(), 555, 4)
+			require.Equal(t, models.ErrBookNotFound, err)
+		})
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		restoreSQL := changeAuditSQL(`UPDATE books SET deleted_at = $2, updated_at = $3, version = version + 1 WHERE (id = $4 AND deleted_at IS NOT NULL)`, 5)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(restoreSQL).WithArgs(444, nil, sqlmock.AnyArg(), 444, "restore", "", "").
+				WillReturnError(fmt.Errorf("some-restore-error"))
+			err := bookRepository.Restore(context.TODO(), 444)
+			require.EqualError(t, err, "some-restore-error")
+		})
+
+		t.Run("not in trash", func(t *testing.T) {
+			mock.ExpectExec(restoreSQL).WithArgs(444, nil, sqlmock.AnyArg(), 444, "restore", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			err := bookRepository.Restore(context.TODO(), 444)
+			require.Equal(t, models.ErrBookNotFound, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(restoreSQL).WithArgs(444, nil, sqlmock.AnyArg(), 444, "restore", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			err := bookRepository.Restore(context.TODO(), 444)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		purgeSQL := regexp.QuoteMeta(`WITH old_book AS (DELETE FROM books WHERE deleted_at < $1 RETURNING *) ` +
+			`INSERT INTO book_audit (book_id,action,old_value,new_value,actor,request_id) ` +
+			`SELECT id, $2, to_jsonb(old_book) - 'search_vector', NULL, $3, $4 FROM old_book`)
+		before := time.Now()
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(purgeSQL).WithArgs(before, "purge", "", "").
+				WillReturnError(fmt.Errorf("some-purge-error"))
+			_, err := bookRepository.Purge(context.TODO(), before)
+			require.EqualError(t, err, "some-purge-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(purgeSQL).WithArgs(before, "purge", "", "").
+				WillReturnResult(sqlmock.NewResult(0, 3))
+			purged, err := bookRepository.Purge(context.TODO(), before)
+			require.NoError(t, err)
+			require.Equal(t, int64(3), purged)
+		})
+	})
+
+	t.Run("History", func(t *testing.T) {
+		historySQL := regexp.QuoteMeta(`SELECT id, book_id, action, old_value, new_value, actor, request_id, created_at, COUNT(*) OVER () ` +
+			`FROM book_audit WHERE book_id = $1 ORDER BY id DESC LIMIT 20 OFFSET 0`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(historySQL).WithArgs(7).WillReturnError(fmt.Errorf("some-history-error"))
+			_, _, err := bookRepository.History(context.TODO(), 7, models.BookCriteria{Page: 1, Size: 20})
+			require.EqualError(t, err, "some-history-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(historySQL).WithArgs(7).
+				WillReturnRows(sqlmock.NewRows(append(repository.BookAuditColumns, "count")).
+					AddRow(12, 7, "patch", []byte(`{"title": "old"}`), []byte(`{"title": "new"}`), "some-actor", "some-request-id", now, 2).
+					AddRow(10, 7, "insert", nil, []byte(`{"title": "old"}`), "some-actor", "", now, 2))
+
+			audits, total, err := bookRepository.History(context.TODO(), 7, models.BookCriteria{Page: 1, Size: 20})
 			require.NoError(t, err)
+			require.Equal(t, int64(2), total)
+			require.Equal(t, []*models.BookAudit{
+				{Revision: 12, BookID: 7, Action: "patch", OldValue: []byte(`{"title": "old"}`), NewValue: []byte(`{"title": "new"}`),
+					Actor: "some-actor", RequestID: "some-request-id", CreatedAt: now},
+				{Revision: 10, BookID: 7, Action: "insert", NewValue: []byte(`{"title": "old"}`), Actor: "some-actor", CreatedAt: now},
+			}, audits)
 		})
 	})
 
+	t.Run("FindRevision", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, book_id, action, old_value, new_value, actor, request_id, created_at `+
+			`FROM book_audit WHERE book_id = $1 AND id = $2`)).
+			WithArgs(7, 10).
+			WillReturnRows(sqlmock.NewRows(repository.BookAuditColumns).
+				AddRow(10, 7, "insert", nil, []byte(`{"title": "old"}`), "some-actor", "", now))
+
+		audit, err := bookRepository.FindRevision(context.TODO(), 7, 10)
+		require.NoError(t, err)
+		require.Equal(t, &models.BookAudit{Revision: 10, BookID: 7, Action: "insert", NewValue: []byte(`{"title": "old"}`),
+			Actor: "some-actor", CreatedAt: now}, audit)
+	})
+
 	t.Run("Find", func(t *testing.T) {
-		querySQL := regexp.QuoteMeta(`SELECT id, title, author, updated_at, created_at FROM books WHERE id = $1`)
+		querySQL := regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books WHERE deleted_at IS NULL AND id = $1`)
 
 		t.Run("sql error", func(t *testing.T) {
 			mock.ExpectQuery(querySQL).WithArgs(123).
 				WillReturnError(fmt.Errorf("some-find-error"))
 
-			_, err := bookRepository.Find(123)
+			_, err := bookRepository.Find(context.TODO(), 123)
 			require.EqualError(t, err, "some-find-error")
 		})
 
@@ -94,25 +411,62 @@ func TestBookRepository(t *testing.T) {
 				ID:        123,
 				Title:     "some-title",
 				Author:    "some-author",
+				Version:   3,
 				UpdatedAt: time.Now(),
 				CreatedAt: time.Now(),
 			}
 			mock.ExpectQuery(querySQL).WithArgs(123).
 				WillReturnRows(sqlmock.NewRows(repository.BookColumns).
-					AddRow(expected.ID, expected.Title, expected.Author, expected.UpdatedAt, expected.CreatedAt))
+					AddRow(expected.ID, expected.Title, expected.Author, expected.ISBN, expected.PublishedYear, expected.Publisher,
+					expected.Language, expected.PageCount, expected.Version, expected.UpdatedAt, expected.CreatedAt, expected.DeletedAt)).
+				RowsWillBeClosed()
 
-			book, err := bookRepository.Find(123)
+			book, err := bookRepository.Find(context.TODO(), 123)
 			require.NoError(t, err)
 			require.Equal(t, expected, book)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+
+		t.Run("rows error", func(t *testing.T) {
+			mock.ExpectQuery(querySQL).WithArgs(123).
+				WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+					AddRow(123, "some-title", "some-author", "", 0, "", "", 0, 3, time.Now(), time.Now(), nil).
+					RowError(0, fmt.Errorf("some-rows-error")))
+
+			_, err := bookRepository.Find(context.TODO(), 123)
+			require.EqualError(t, err, "some-rows-error")
+		})
+	})
+
+	t.Run("FindByISBN", func(t *testing.T) {
+		querySQL := regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, ` +
+			`updated_at, created_at, deleted_at FROM books WHERE deleted_at IS NULL AND isbn = $1`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(querySQL).WithArgs("080442957X").
+				WillReturnError(fmt.Errorf("some-find-error"))
+
+			_, err := bookRepository.FindByISBN(context.TODO(), "0-8044-2957-x")
+			require.EqualError(t, err, "some-find-error")
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectQuery(querySQL).WithArgs("080442957X").
+				WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+			book, err := bookRepository.FindByISBN(context.TODO(), "080442957X")
+			require.NoError(t, err)
+			require.Nil(t, book)
 		})
 	})
 
 	t.Run("list", func(t *testing.T) {
-		listSQL := `SELECT id, title, author, updated_at, created_at FROM books`
+		listSQL := regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books WHERE (deleted_at IS NULL) ORDER BY id ASC LIMIT 20 OFFSET 0`)
+		criteria := models.BookCriteria{Page: 1, Size: 20}
 
 		t.Run("sql error", func(t *testing.T) {
 			mock.ExpectQuery(listSQL).WillReturnError(fmt.Errorf("some-list-error"))
-			_, err := bookRepository.List()
+			_, err := bookRepository.List(context.TODO(), criteria)
 			require.EqualError(t, err, "some-list-error")
 		})
 
@@ -124,12 +478,13 @@ func TestBookRepository(t *testing.T) {
 
 			rows := sqlmock.NewRows(repository.BookColumns)
 			for _, expected := range expecteds {
-				rows.AddRow(expected.ID, expected.Title, expected.Author, expected.UpdatedAt, expected.CreatedAt)
+				rows.AddRow(expected.ID, expected.Title, expected.Author, expected.ISBN, expected.PublishedYear, expected.Publisher,
+					expected.Language, expected.PageCount, expected.Version, expected.UpdatedAt, expected.CreatedAt, expected.DeletedAt)
 			}
 
 			mock.ExpectQuery(listSQL).WillReturnRows(rows)
 
-			books, err := bookRepository.List()
+			books, err := bookRepository.List(context.TODO(), criteria)
 			require.NoError(t, err)
 			require.Equal(t, expecteds, books)
 		})
@@ -140,10 +495,246 @@ func TestBookRepository(t *testing.T) {
 				AddRow(1, "one").
 				AddRow(2, "two"))
 
-			_, err := bookRepository.List()
-			require.EqualError(t, err, "sql: expected 2 destination arguments in Scan, not 5")
+			_, err := bookRepository.List(context.TODO(), criteria)
+			require.EqualError(t, err, "sql: expected 2 destination arguments in Scan, not 12")
+
+		})
+
+		t.Run("filter and sort", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books ` +
+				`WHERE (deleted_at IS NULL AND title ILIKE $1 AND author = $2) ORDER BY author DESC, title ASC, id ASC LIMIT 10 OFFSET 20`)).
+				WithArgs(`%50\%%`, "some-author").
+				WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+			books, err := bookRepository.List(context.TODO(), models.BookCriteria{
+				TitleLike: "50%",
+				Author:    "some-author",
+				Sort:      "-author,title",
+				Page:      3,
+				Size:      10,
+			})
+			require.NoError(t, err)
+			require.Empty(t, books)
+		})
+
+		t.Run("sparse fieldset", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title FROM books WHERE (deleted_at IS NULL) ORDER BY id ASC LIMIT 20 OFFSET 0`)).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "some-title"))
 
+			books, err := bookRepository.List(context.TODO(), models.BookCriteria{Fields: []string{"title"}, Page: 1, Size: 20})
+			require.NoError(t, err)
+			require.Equal(t, []*models.Book{{ID: 1, Title: "some-title"}}, books)
 		})
 
+		t.Run("invalid sort", func(t *testing.T) {
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{Sort: "password"})
+			require.True(t, errors.Is(err, models.ErrInvalidSort))
+			require.EqualError(t, err, "invalid sort column: 'password'")
+		})
+	})
+
+	t.Run("list by keyset", func(t *testing.T) {
+		t.Run("first page", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books WHERE (deleted_at IS NULL) ORDER BY id ASC LIMIT 3`)).
+				WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{Keyset: true, Size: 3})
+			require.NoError(t, err)
+		})
+
+		t.Run("after cursor", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books `+
+				`WHERE (deleted_at IS NULL AND author = $1 AND (title, id) < ($2, $3)) ORDER BY title DESC, id DESC LIMIT 3`)).
+				WithArgs("some-author", "some-title", 77).
+				WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{
+				Author: "some-author",
+				Sort:   "-title",
+				Keyset: true,
+				After:  &models.Cursor{Column: "title", Desc: true, Value: "some-title", ID: 77},
+				Size:   3,
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("sparse fieldset", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, author, isbn FROM books WHERE (deleted_at IS NULL) ORDER BY isbn ASC, id ASC LIMIT 3`)).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "author", "isbn"}))
+
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{Fields: []string{"author"}, Sort: "isbn", Keyset: true, Size: 3})
+			require.NoError(t, err)
+		})
+
+		t.Run("cursor not match sort", func(t *testing.T) {
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{
+				Sort:   "title",
+				Keyset: true,
+				After:  &models.Cursor{Column: "author", Value: "some-author", ID: 77},
+			})
+			require.Equal(t, models.ErrInvalidCursor, err)
+		})
+
+		t.Run("multiple sort", func(t *testing.T) {
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{Sort: "title,author", Keyset: true})
+			require.True(t, errors.Is(err, models.ErrInvalidSort))
+		})
+
+		t.Run("nullable sort", func(t *testing.T) {
+			_, err := bookRepository.List(context.TODO(), models.BookCriteria{Sort: "-deleted_at", Keyset: true, Trashed: true})
+			require.True(t, errors.Is(err, models.ErrInvalidSort))
+			require.EqualError(t, err, "invalid sort column: 'deleted_at'")
+		})
+	})
+
+	t.Run("search", func(t *testing.T) {
+		searchSQL := regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at, ` +
+			`ts_rank(search_vector, query) AS rank, ` +
+			`ts_headline('english', title, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'), ` +
+			`ts_headline('english', author, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'), ` +
+			`COUNT(*) OVER () FROM books CROSS JOIN websearch_to_tsquery('english', $1) AS query ` +
+			`WHERE (deleted_at IS NULL AND author = $2 AND search_vector @@ query) ORDER BY rank DESC, id ASC LIMIT 20 OFFSET 0`)
+		criteria := models.BookCriteria{Author: "some-author", Page: 1, Size: 20}
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(searchSQL).WithArgs("hobbit", "some-author").
+				WillReturnError(fmt.Errorf("some-search-error"))
+			_, _, err := bookRepository.Search(context.TODO(), "hobbit", criteria)
+			require.EqualError(t, err, "some-search-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(searchSQL).WithArgs("hobbit", "some-author").
+				WillReturnRows(sqlmock.NewRows(append(repository.BookColumns, "rank", "title", "author", "count")).
+					AddRow(1, "The Hobbit", "some-author", "", 0, "", "", 0, 2, now, now, nil, 0.6, "The <mark>Hobbit</mark>", "some-author", 1))
+
+			results, total, err := bookRepository.Search(context.TODO(), "hobbit", criteria)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), total)
+			require.Equal(t, []*models.BookSearchResult{
+				{
+					Book:       models.Book{ID: 1, Title: "The Hobbit", Author: "some-author", Version: 2, UpdatedAt: now, CreatedAt: now},
+					Rank:       0.6,
+					Highlights: models.BookHighlights{Title: "The <mark>Hobbit</mark>", Author: "some-author"},
+				},
+			}, results)
+		})
+	})
+
+	t.Run("list by author", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books ` +
+			`WHERE (deleted_at IS NULL AND id IN (SELECT book_id FROM book_authors WHERE author_id = $1)) ORDER BY id ASC LIMIT 20 OFFSET 0`)).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+		_, err := bookRepository.List(context.TODO(), models.BookCriteria{AuthorID: 7, Page: 1, Size: 20})
+		require.NoError(t, err)
+	})
+
+	t.Run("list by tag and category", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books ` +
+			`WHERE (deleted_at IS NULL AND id IN (SELECT book_id FROM book_tags WHERE tag = $1) AND id IN (SELECT book_id FROM book_tags WHERE tag = $2) ` +
+			`AND id IN (WITH RECURSIVE tree AS (SELECT id FROM categories WHERE id = $3 UNION SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id) ` +
+			`SELECT bc.book_id FROM book_categories bc JOIN tree t ON t.id = bc.category_id)) ORDER BY id ASC LIMIT 20 OFFSET 0`)).
+			WithArgs("classic", "hobbit", 3).
+			WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+		_, err := bookRepository.List(context.TODO(), models.BookCriteria{Tags: []string{"classic", "hobbit"}, CategoryID: 3, Page: 1, Size: 20})
+		require.NoError(t, err)
+	})
+
+	t.Run("list trash", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books ` +
+			`WHERE (deleted_at IS NOT NULL) ORDER BY deleted_at DESC, id ASC LIMIT 20 OFFSET 0`)).
+			WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+
+		_, err := bookRepository.List(context.TODO(), models.BookCriteria{Trashed: true, Sort: "-deleted_at", Page: 1, Size: 20})
+		require.NoError(t, err)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		streamSQL := regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books ` +
+			`WHERE (deleted_at IS NULL) ORDER BY id ASC`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(streamSQL).WillReturnError(fmt.Errorf("some-stream-error"))
+			err := bookRepository.Stream(context.TODO(), models.BookCriteria{}, func(*models.Book) error { return nil })
+			require.EqualError(t, err, "some-stream-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(streamSQL).WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+				AddRow(1, "some-title1", "some-author1", "", 0, "", "", 0, 1, now, now, nil).
+				AddRow(2, "some-title2", "some-author2", "", 0, "", "", 0, 1, now, now, nil))
+
+			var titles []string
+			err := bookRepository.Stream(context.TODO(), models.BookCriteria{}, func(book *models.Book) error {
+				titles = append(titles, book.Title)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{"some-title1", "some-title2"}, titles)
+		})
+
+		t.Run("callback error", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(streamSQL).WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+				AddRow(1, "some-title1", "some-author1", "", 0, "", "", 0, 1, now, now, nil))
+
+			err := bookRepository.Stream(context.TODO(), models.BookCriteria{}, func(*models.Book) error {
+				return fmt.Errorf("some-write-error")
+			})
+			require.EqualError(t, err, "some-write-error")
+		})
+	})
+
+	t.Run("count", func(t *testing.T) {
+		countSQL := regexp.QuoteMeta(`SELECT COUNT(*) FROM books WHERE (deleted_at IS NULL AND title = $1)`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(countSQL).WithArgs("some-title").WillReturnError(fmt.Errorf("some-count-error"))
+			_, err := bookRepository.Count(context.TODO(), models.BookCriteria{Title: "some-title"})
+			require.EqualError(t, err, "some-count-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(countSQL).WithArgs("some-title").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+			total, err := bookRepository.Count(context.TODO(), models.BookCriteria{Title: "some-title"})
+			require.NoError(t, err)
+			require.Equal(t, int64(42), total)
+		})
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		t.Run("list", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT book_id, tag FROM book_tags WHERE book_id IN ($1,$2) ORDER BY book_id, tag`)).
+				WithArgs(1, 2).
+				WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}).
+					AddRow(1, "classic").
+					AddRow(1, "fantasy").
+					AddRow(2, "classic"))
+			tags, err := bookRepository.ListTags(context.TODO(), []int64{1, 2})
+			require.NoError(t, err)
+			require.Equal(t, map[int64][]string{1: {"classic", "fantasy"}, 2: {"classic"}}, tags)
+		})
+
+		t.Run("add", func(t *testing.T) {
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO book_tags (book_id,tag) VALUES ($1,$2),($3,$4) ON CONFLICT DO NOTHING`)).
+				WithArgs(1, "classic", 1, "fantasy").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			err := bookRepository.AddTags(context.Background(), 1, []string{"classic", "fantasy"})
+			require.NoError(t, err)
+		})
+
+		t.Run("remove", func(t *testing.T) {
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM book_tags WHERE book_id = $1 AND tag IN ($2)`)).
+				WithArgs(1, "classic").
+				WillReturnError(fmt.Errorf("some-remove-error"))
+			err := bookRepository.RemoveTags(context.Background(), 1, []string{"classic"})
+			require.EqualError(t, err, "some-remove-error")
+		})
 	})
 }
@@ -1,22 +1,79 @@
 package repository
 
+import "github.com/typical-go/typical-rest-server/app/book/models"
+
 // Table Name
 const (
-	bookTable = "books"
+	bookTable      = "books"
+	bookTagTable   = "book_tags"
+	bookAuditTable = "book_audit"
 )
 
 // Table Column Names
 const (
 	idColumn        = "id"
+	versionColumn   = "version"
 	updatedAtColumn = "updated_at"
 	createdAtColumn = "created_at"
+	deletedAtColumn = "deleted_at"
 
 	// Book Table Column Names
-	bookTitleColumn  = "title"
-	bookAuthorColumn = "author"
+	bookTitleColumn         = "title"
+	bookAuthorColumn        = "author"
+	bookISBNColumn          = "isbn"
+	bookPublishedYearColumn = "published_year"
+	bookPublisherColumn     = "publisher"
+	bookLanguageColumn      = "language"
+	bookPageCountColumn     = "page_count"
+	bookSearchColumn        = "search_vector"
+
+	// Book Tag Table Column Names
+	bookIDColumn  = "book_id"
+	bookTagColumn = "tag"
+
+	// Book Audit Table Column Names
+	auditActionColumn    = "action"
+	auditOldValueColumn  = "old_value"
+	auditNewValueColumn  = "new_value"
+	auditActorColumn     = "actor"
+	auditRequestIDColumn = "request_id"
+)
+
+// Constraint Names
+const (
+	bookISBNConstraint = "books_isbn_key"
+
+	// uniqueViolation is postgres error code when unique constraint is violated
+	uniqueViolation = "23505"
 )
 
+// naturalKeys is natural key supported by upsert
+var naturalKeys = map[models.NaturalKey]bool{
+	models.NaturalKeyISBN:        true,
+	models.NaturalKeyTitleAuthor: true,
+}
+
+// Full-text search
+const (
+	searchConfig   = "english"
+	searchHeadline = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+)
+
+// categoryBooksQuery select id of books in the category or any of its descendants
+const categoryBooksQuery = `WITH RECURSIVE tree AS (` +
+	`SELECT id FROM categories WHERE id = ? ` +
+	`UNION SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id` +
+	`) SELECT bc.book_id FROM book_categories bc JOIN tree t ON t.id = bc.category_id`
+
 // Table Columns
 var (
-	BookColumns = []string{idColumn, bookTitleColumn, bookAuthorColumn, updatedAtColumn, createdAtColumn}
+	BookColumns = []string{idColumn, bookTitleColumn, bookAuthorColumn, bookISBNColumn, bookPublishedYearColumn, bookPublisherColumn,
+		bookLanguageColumn, bookPageCountColumn, versionColumn, updatedAtColumn, createdAtColumn, deletedAtColumn}
+
+	BookAuditColumns = []string{idColumn, bookIDColumn, auditActionColumn, auditOldValueColumn, auditNewValueColumn,
+		auditActorColumn, auditRequestIDColumn, createdAtColumn}
+
+	// bookValueColumns is columns which value is given by client
+	bookValueColumns = []string{bookTitleColumn, bookAuthorColumn, bookISBNColumn, bookPublishedYearColumn, bookPublisherColumn,
+		bookLanguageColumn, bookPageCountColumn}
 )
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
+)
+
+//BulkBook func
+func (r *InitBookService) BulkBook(ctx context.Context, request models.BulkRequest) (results []*models.BulkResult, ok bool) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	atomic := request.Mode != models.BulkBestEffort
+	ok = true
+
+	for i, operation := range request.Operations {
+		result := &models.BulkResult{Index: i, Op: operation.Op, ID: operation.Book.ID}
+		results = append(results, result)
+
+		if atomic && !ok {
+			result.Status = models.BulkSkipped
+			continue
+		}
+
+		if err := operation.Validate(); err != nil {
+			result.Status, result.Err = models.BulkInvalid, err
+			ok = false
+			continue
+		}
+
+		var rollback dbtrxn.RollbackFn
+		if !atomic {
+			var err error
+			if rollback, err = dbtrxn.Savepoint(ctx, fmt.Sprintf("bulk_%d", i)); err != nil {
+				result.Status, result.Err = models.BulkFailed, err
+				ok = false
+				continue
+			}
+		}
+
+		if err := r.bulkExecute(ctx, operation, result); err != nil {
+			result.Status, result.Err = bulkFailure(err), err
+			ok = false
+			if rollback != nil {
+				rollback()
+			}
+		}
+	}
+
+	// NOTE: atomic bulk is rolled back entirely when any operation is failed
+	if atomic && !ok {
+		dbtrxn.SetError(ctx, fmt.Errorf("bulk: atomic operations failed"))
+		for _, result := range results {
+			switch result.Status {
+			case models.BulkCreated, models.BulkUpdated, models.BulkDeleted:
+				result.Status = models.BulkRolledBack
+			}
+		}
+	}
+
+	zerolog.Ctx(ctx).Info().Int("operations", len(request.Operations)).Str("mode", request.Mode).Bool("ok", ok).Msg("bulk executed")
+	return results, ok
+}
+
+func (r *InitBookService) bulkExecute(ctx context.Context, operation models.BulkOperation, result *models.BulkResult) (err error) {
+	switch operation.Op {
+	case models.BulkCreate:
+		if result.ID, err = r.insertBook(ctx, operation.Book); err == nil {
+			result.Status = models.BulkCreated
+		}
+	case models.BulkUpdate:
+		if err = r.updateBook(ctx, operation.Book); err == nil {
+			result.Status = models.BulkUpdated
+		}
+	case models.BulkDelete:
+		if err = r.Repository.Book.Delete(ctx, operation.Book.ID, operation.Book.Version); err == nil {
+			result.Status = models.BulkDeleted
+		}
+	}
+	return
+}
+
+// bulkFailure return status of the operation which is failed by the error; missing or trashed book is reported separately
+func bulkFailure(err error) string {
+	if errors.Is(err, models.ErrBookNotFound) {
+		return models.BulkNotFound
+	}
+	return models.BulkFailed
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/book/service"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+func TestBookServiceBulkBook(t *testing.T) {
+	insertSQL := regexp.QuoteMeta(`WITH new_book AS (INSERT INTO books (title,author,isbn,published_year,publisher,language,page_count) ` +
+		`VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING *) INSERT INTO book_audit`)
+	authorSQL := regexp.QuoteMeta(`INSERT INTO authors (name) VALUES ($1) ON CONFLICT (name) DO UPDATE`)
+	linkSQL := regexp.QuoteMeta(`INSERT INTO book_authors (book_id,author_id,position) VALUES ($1,$2,$3)`)
+	deleteSQL := regexp.QuoteMeta(`WITH old_book AS (SELECT * FROM books WHERE id = $1 FOR UPDATE), ` +
+		`new_book AS (UPDATE books SET deleted_at = $2, version = version + 1 WHERE deleted_at IS NULL AND id = $3 RETURNING *) INSERT INTO book_audit`)
+
+	t.Run("atomic", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(authorSQL).WithArgs("some-author").
+			WillReturnRows(sqlmock.NewRows(authorrepo.AuthorColumns).AddRow(20, "some-author", time.Now(), time.Now()))
+		mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author", "", 0, "", "", 0, "insert", "", "").
+			WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(10))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(10).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(linkSQL).WithArgs(10, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		results, ok := s.BulkBook(context.Background(), models.BulkRequest{
+			Mode: models.BulkAtomic,
+			Operations: []models.BulkOperation{
+				{Op: models.BulkCreate, Book: models.Book{Title: "some-title", Author: "some-author"}},
+				{Op: models.BulkCreate, Book: models.Book{Title: "no-author"}},
+				{Op: models.BulkDelete, Book: models.Book{ID: 3}},
+			},
+		})
+		require.False(t, ok)
+		require.Equal(t, []string{"", "author_required", ""}, bulkRules(results))
+		require.Equal(t, []*models.BulkResult{
+			{Index: 0, Op: models.BulkCreate, ID: 10, Status: models.BulkRolledBack},
+			{Index: 1, Op: models.BulkCreate, Status: models.BulkInvalid},
+			{Index: 2, Op: models.BulkDelete, ID: 3, Status: models.BulkSkipped},
+		}, results)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(authorSQL).WithArgs("some-author").
+			WillReturnRows(sqlmock.NewRows(authorrepo.AuthorColumns).AddRow(20, "some-author", time.Now(), time.Now()))
+		mock.ExpectQuery(insertSQL).WithArgs("some-title", "some-author", "", 0, "", "", 0, "insert", "", "").
+			WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(10))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(10).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(linkSQL).WithArgs(10, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SAVEPOINT bulk_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteSQL).WithArgs(3, sqlmock.AnyArg(), 3, "delete", "", "").WillReturnError(fmt.Errorf("some-delete-error"))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT bulk_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT bulk_2").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteSQL).WithArgs(4, sqlmock.AnyArg(), 4, "delete", "", "").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT bulk_2").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		results, ok := s.BulkBook(context.Background(), models.BulkRequest{
+			Mode: models.BulkBestEffort,
+			Operations: []models.BulkOperation{
+				{Op: models.BulkCreate, Book: models.Book{Title: "some-title", Author: "some-author"}},
+				{Op: models.BulkDelete, Book: models.Book{ID: 3}},
+				{Op: models.BulkDelete, Book: models.Book{ID: 4}},
+				{Op: "unknown"},
+			},
+		})
+		require.False(t, ok)
+		require.Equal(t, []string{"", "some-delete-error", "book not found", "unknown op 'unknown'"}, bulkRules(results))
+		require.Equal(t, []*models.BulkResult{
+			{Index: 0, Op: models.BulkCreate, ID: 10, Status: models.BulkCreated},
+			{Index: 1, Op: models.BulkDelete, ID: 3, Status: models.BulkFailed},
+			{Index: 2, Op: models.BulkDelete, ID: 4, Status: models.BulkNotFound},
+			{Index: 3, Op: "unknown", Status: models.BulkInvalid},
+		}, results)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// bulkRules return the failed validation rule or the error message of each result; the error is cleared to compare the rest
+func bulkRules(results []*models.BulkResult) []string {
+	rules := make([]string, len(results))
+	for i, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		if fieldErrs := validkit.FieldErrors(result.Err, validkit.Translator("")); len(fieldErrs) > 0 {
+			rules[i] = fieldErrs[0].Rule
+		} else {
+			rules[i] = result.Err.Error()
+		}
+		result.Err = nil
+	}
+	return rules
+}
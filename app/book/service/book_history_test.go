@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/book/service"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
+)
+
+func TestBookServiceRevertBook(t *testing.T) {
+	revisionSQL := regexp.QuoteMeta(`SELECT id, book_id, action, old_value, new_value, actor, request_id, created_at ` +
+		`FROM book_audit WHERE book_id = $1 AND id = $2`)
+	authorSQL := regexp.QuoteMeta(`INSERT INTO authors (name) VALUES ($1) ON CONFLICT (name) DO UPDATE`)
+	linkSQL := regexp.QuoteMeta(`INSERT INTO book_authors (book_id,author_id,position) VALUES ($1,$2,$3)`)
+	bookAuthorRows := func(bookID, authorID int64, name string) *sqlmock.Rows {
+		return sqlmock.NewRows(append([]string{"book_id"}, authorrepo.AuthorColumns...)).AddRow(bookID, authorID, name, time.Now(), time.Now())
+	}
+
+	t.Run("revision not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(revisionSQL).WithArgs(7, 10).WillReturnRows(sqlmock.NewRows(repository.BookAuditColumns))
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		_, err = s.RevertBook(context.Background(), 7, 10, 3)
+		require.Equal(t, models.ErrRevisionNotFound, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("purged revision", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(revisionSQL).WithArgs(7, 10).WillReturnRows(sqlmock.NewRows(repository.BookAuditColumns).
+			AddRow(10, 7, models.AuditPurge, []byte(`{"title": "old"}`), nil, "cli:purge", "", time.Now()))
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		_, err = s.RevertBook(context.Background(), 7, 10, 3)
+		require.Equal(t, models.ErrRevisionNotFound, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(revisionSQL).WithArgs(7, 10).WillReturnRows(sqlmock.NewRows(repository.BookAuditColumns).
+			AddRow(10, 7, models.AuditUpdate, nil, []byte(`{"title": "old-title", "author": "old-author", "page_count": 120}`), "", "", time.Now()))
+		mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows(7, 20, "old-author"))
+		mock.ExpectBegin()
+		mock.ExpectExec(`WITH old_book AS \(SELECT \* FROM books WHERE id = \$1 FOR UPDATE\)`).
+			WithArgs(7, "old-title", "old-author", "", 0, "", "", 120, sqlmock.AnyArg(), 7, 3, models.AuditRevert, "", "").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM books WHERE deleted_at IS NULL AND id = $1`)).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectRollback()
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		_, err = s.RevertBook(context.Background(), 7, 10, 3)
+		require.Equal(t, models.ErrVersionConflict, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(revisionSQL).WithArgs(7, 10).WillReturnRows(sqlmock.NewRows(repository.BookAuditColumns).
+			AddRow(10, 7, models.AuditUpdate, nil, []byte(`{"title": "old-title", "author": "old-author", "page_count": 120}`), "", "", time.Now()))
+		mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows(7, 20, "old-author"))
+		mock.ExpectBegin()
+		mock.ExpectExec(`WITH old_book AS \(SELECT \* FROM books WHERE id = \$1 FOR UPDATE\)`).
+			WithArgs(7, "old-title", "old-author", "", 0, "", "", 120, sqlmock.AnyArg(), 7, 3, models.AuditRevert, "", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(linkSQL).WithArgs(7, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM books WHERE deleted_at IS NULL AND id = $1`)).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+				AddRow(7, "old-title", "old-author", "", 0, "", "", 120, 4, time.Now(), time.Now(), nil))
+		mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id"}))
+		mock.ExpectQuery(`FROM book_categories`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id"}))
+		mock.ExpectQuery(`FROM book_tags`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}))
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		book, err := s.RevertBook(context.Background(), 7, 10, 3)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), book.Version)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("revert across author change", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(revisionSQL).WithArgs(7, 10).WillReturnRows(sqlmock.NewRows(repository.BookAuditColumns).
+			AddRow(10, 7, models.AuditUpdate, nil, []byte(`{"title": "old-title", "author": "old-author", "page_count": 120}`), "", "", time.Now()))
+		mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows(7, 21, "new-author"))
+		mock.ExpectBegin()
+		mock.ExpectQuery(authorSQL).WithArgs("old-author").
+			WillReturnRows(sqlmock.NewRows(authorrepo.AuthorColumns).AddRow(20, "old-author", time.Now(), time.Now()))
+		mock.ExpectExec(`WITH old_book AS \(SELECT \* FROM books WHERE id = \$1 FOR UPDATE\)`).
+			WithArgs(7, "old-title", "old-author", "", 0, "", "", 120, sqlmock.AnyArg(), 7, 3, models.AuditRevert, "", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(linkSQL).WithArgs(7, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM books WHERE deleted_at IS NULL AND id = $1`)).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+				AddRow(7, "old-title", "old-author", "", 0, "", "", 120, 4, time.Now(), time.Now(), nil))
+		mock.ExpectQuery(`FROM book_authors`).WithArgs(7).WillReturnRows(bookAuthorRows(7, 20, "old-author"))
+		mock.ExpectQuery(`FROM book_categories`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id"}))
+		mock.ExpectQuery(`FROM book_tags`).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}))
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		book, err := s.RevertBook(context.Background(), 7, 10, 3)
+		require.NoError(t, err)
+		require.Equal(t, "old-author", book.Author)
+		require.Len(t, book.Authors, 1)
+		require.Equal(t, int64(20), book.Authors[0].ID)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
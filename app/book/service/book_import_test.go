@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/book/service"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
+)
+
+func TestBookServiceImportBook(t *testing.T) {
+	batchSQL := regexp.QuoteMeta(`WITH new_book AS (INSERT INTO books (title,author,isbn,published_year,publisher,language,page_count) ` +
+		`VALUES ($1,$2,$3,$4,$5,$6,$7),($8,$9,$10,$11,$12,$13,$14) RETURNING *) INSERT INTO book_audit`)
+	insertSQL := regexp.QuoteMeta(`WITH new_book AS (INSERT INTO books (title,author,isbn,published_year,publisher,language,page_count) ` +
+		`VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING *) INSERT INTO book_audit`)
+	authorSQL := regexp.QuoteMeta(`INSERT INTO authors (name) VALUES ($1) ON CONFLICT (name) DO UPDATE`)
+	linkSQL := regexp.QuoteMeta(`INSERT INTO book_authors (book_id,author_id,position) VALUES ($1,$2,$3)`)
+	authorRows := func(id int64, name string) *sqlmock.Rows {
+		return sqlmock.NewRows(authorrepo.AuthorColumns).AddRow(id, name, time.Now(), time.Now())
+	}
+	books := []models.Book{
+		{Title: "some-title1", Author: "some-author1"},
+		{Title: "some-title2", Author: "some-author2", ISBN: "9780306406157"},
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(authorSQL).WithArgs("some-author1").WillReturnRows(authorRows(20, "some-author1"))
+		mock.ExpectQuery(authorSQL).WithArgs("some-author2").WillReturnRows(authorRows(21, "some-author2"))
+		mock.ExpectQuery(batchSQL).WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(10).AddRow(11))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(10).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(linkSQL).WithArgs(10, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(11).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(linkSQL).WithArgs(11, 21, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		ids, errs := s.ImportBook(context.Background(), books)
+		require.Equal(t, []int64{10, 11}, ids)
+		require.Equal(t, []error{nil, nil}, errs)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("row by row", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(authorSQL).WithArgs("some-author1").WillReturnRows(authorRows(20, "some-author1"))
+		mock.ExpectQuery(authorSQL).WithArgs("some-author2").WillReturnRows(authorRows(21, "some-author2"))
+		mock.ExpectQuery(batchSQL).WillReturnError(&pq.Error{Code: "23505", Constraint: "books_isbn_key"})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectQuery(authorSQL).WithArgs("some-author1").WillReturnRows(authorRows(20, "some-author1"))
+		mock.ExpectQuery(insertSQL).WithArgs("some-title1", "some-author1", "", 0, "", "", 0, "insert", "", "").
+			WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(10))
+		mock.ExpectExec(`DELETE FROM book_authors`).WithArgs(10).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(linkSQL).WithArgs(10, 20, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SAVEPOINT import_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(authorSQL).WithArgs("some-author2").WillReturnRows(authorRows(21, "some-author2"))
+		mock.ExpectQuery(insertSQL).WithArgs("some-title2", "some-author2", "9780306406157", 0, "", "", 0, "insert", "", "").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "books_isbn_key"})
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT import_1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		ids, errs := s.ImportBook(context.Background(), books)
+		require.Equal(t, []int64{10, 0}, ids)
+		require.Equal(t, []error{nil, models.ErrDuplicateISBN}, errs)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -2,19 +2,41 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/rs/zerolog"
+	authormodels "github.com/typical-go/typical-rest-server/app/author/models"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
 	"github.com/typical-go/typical-rest-server/app/book/models"
 	"github.com/typical-go/typical-rest-server/app/book/repository"
+	categorymodels "github.com/typical-go/typical-rest-server/app/category/models"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
 	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
 )
 
 //BookService interface
 type BookService interface {
-	CreateBook(book models.Book) (int64, error)
-	GetBook(id int64) (*models.Book, error)
-	ListBook() ([]*models.Book, error)
-	UpdateBook(book models.Book) error
-	DeleteBook(id int64) error
+	CreateBook(ctx context.Context, book models.Book) (int64, error)
+	ImportBook(ctx context.Context, books []models.Book) ([]int64, []error)
+	ExportBook(ctx context.Context, criteria models.BookCriteria, fn func(*models.Book) error) error
+	GetBook(ctx context.Context, id int64) (*models.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (*models.Book, error)
+	ListBook(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, int64, error)
+	ListBookByCursor(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, *models.Cursor, error)
+	SearchBook(ctx context.Context, query string, criteria models.BookCriteria) ([]*models.BookSearchResult, int64, error)
+	UpdateBook(ctx context.Context, book models.Book) error
+	UpsertBook(ctx context.Context, key models.NaturalKey, book models.Book) (int64, bool, error)
+	PatchBook(ctx context.Context, book models.Book, columns []string) error
+	DeleteBook(ctx context.Context, id int64, version int64) error
+	RestoreBook(ctx context.Context, id int64) error
+	BulkBook(ctx context.Context, request models.BulkRequest) ([]*models.BulkResult, bool)
+	PurgeBook(ctx context.Context, retention time.Duration) (int64, error)
+	AttachBookTags(ctx context.Context, id int64, tags []string) ([]string, error)
+	DetachBookTags(ctx context.Context, id int64, tags []string) ([]string, error)
+	BookHistory(ctx context.Context, id int64, criteria models.BookCriteria) ([]*models.BookAudit, int64, error)
+	RevertBook(ctx context.Context, id int64, revision int64, version int64) (*models.Book, error)
 }
 
 //InitBookService struct
@@ -24,74 +46,518 @@ type InitBookService struct {
 
 //InitBookRepositoryInterface struct
 type InitBookRepositoryInterface struct {
-	Book repository.BookRepository
+	Book     repository.BookRepository
+	Author   authorrepo.AuthorRepository
+	Category categoryrepo.CategoryRepository
 }
 
 // NewBookService return new instance of BookRepository
-func NewBookService(bookRepository repository.BookRepository) BookService {
+func NewBookService(
+	bookRepository repository.BookRepository,
+	authorRepository authorrepo.AuthorRepository,
+	categoryRepository categoryrepo.CategoryRepository,
+) BookService {
 	return &InitBookService{
 		Repository: &InitBookRepositoryInterface{
-			Book: bookRepository,
+			Book:     bookRepository,
+			Author:   authorRepository,
+			Category: categoryRepository,
 		},
 	}
 }
 
 //GetBook func
-func (r *InitBookService) GetBook(id int64) (*models.Book, error) {
-	book, err := r.Repository.Book.Find(id)
+func (r *InitBookService) GetBook(ctx context.Context, id int64) (*models.Book, error) {
+	book, err := r.Repository.Book.Find(ctx, id)
+	if err != nil || book == nil {
+		return book, err
+	}
+
+	err = r.embed(ctx, models.BookCriteria{}, book)
+
+	return book, err
+}
+
+//GetBookByISBN func
+func (r *InitBookService) GetBookByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	book, err := r.Repository.Book.FindByISBN(ctx, isbn)
+	if err != nil || book == nil {
+		return book, err
+	}
+
+	err = r.embed(ctx, models.BookCriteria{}, book)
 
 	return book, err
 }
 
 //ListBook func
-func (r *InitBookService) ListBook() ([]*models.Book, error) {
-	books, err := r.Repository.Book.List()
+func (r *InitBookService) ListBook(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, int64, error) {
+	books, err := r.Repository.Book.List(ctx, criteria)
+	if err != nil {
+		return books, 0, err
+	}
+
+	total, err := r.Repository.Book.Count(ctx, criteria)
+	if err != nil {
+		return books, 0, err
+	}
+
+	err = r.embed(ctx, criteria, books...)
+
+	return books, total, err
+}
+
+//ListBookByCursor func
+func (r *InitBookService) ListBookByCursor(ctx context.Context, criteria models.BookCriteria) ([]*models.Book, *models.Cursor, error) {
+	size := criteria.Size
+	criteria.Keyset = true
+	criteria.Size = size + 1 // fetch one more book to know whether next page exist
 
+	books, err := r.Repository.Book.List(ctx, criteria)
 	if err != nil {
-		return books, err
+		return books, nil, err
+	}
+
+	var next *models.Cursor
+	if uint64(len(books)) > size {
+		books = books[:size]
+		column, desc := criteria.KeysetSort()
+		next = models.NewCursor(column, desc, books[size-1])
 	}
 
-	return books, err
+	err = r.embed(ctx, criteria, books...)
+
+	return books, next, err
+}
+
+//SearchBook func
+func (r *InitBookService) SearchBook(ctx context.Context, query string, criteria models.BookCriteria) ([]*models.BookSearchResult, int64, error) {
+	results, total, err := r.Repository.Book.Search(ctx, query, criteria)
+	if err != nil {
+		return results, total, err
+	}
+
+	books := make([]*models.Book, len(results))
+	for i, result := range results {
+		books[i] = &result.Book
+	}
+	err = r.embed(ctx, criteria, books...)
+
+	return results, total, err
 }
 
 //CreateBook func
-func (r *InitBookService) CreateBook(book models.Book) (int64, error) {
+func (r *InitBookService) CreateBook(ctx context.Context, book models.Book) (int64, error) {
 	//start transaction
-	ctx := context.Background()
 	defer dbtrxn.Begin(&ctx)()
 
-	result, err := r.Repository.Book.Insert(ctx, book)
+	result, err := r.insertBook(ctx, book)
 
 	//transaction commit or rollback if error
 	dbtrxn.Error(ctx)
 
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("book_id", result).Msg("book created")
+	}
 	return result, err
 }
 
+//ImportBook func
+func (r *InitBookService) ImportBook(ctx context.Context, books []models.Book) (ids []int64, errs []error) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	ids, errs = make([]int64, len(books)), make([]error, len(books))
+
+	// NOTE: the batch is inserted in single query and only fall back to row by row when any row is failed
+	rollback, err := dbtrxn.Savepoint(ctx, "import_batch")
+	if err == nil {
+		if err = r.importBatch(ctx, books, ids); err == nil {
+			zerolog.Ctx(ctx).Info().Int("count", len(books)).Msg("books imported")
+			return ids, errs
+		}
+		err = rollback()
+	}
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return ids, errs
+	}
+
+	// NOTE: savepoint of each row so the error belong to the row which cause it and the other rows are kept
+	count := 0
+	for i, book := range books {
+		if rollback, err = dbtrxn.Savepoint(ctx, fmt.Sprintf("import_%d", i)); err == nil {
+			if ids[i], err = r.insertBook(ctx, book); err != nil {
+				rollback()
+			}
+		}
+		if errs[i] = err; err == nil {
+			count++
+		}
+	}
+
+	zerolog.Ctx(ctx).Info().Int("count", count).Int("failed", len(books)-count).Msg("books imported")
+	return ids, errs
+}
+
+//ExportBook func
+func (r *InitBookService) ExportBook(ctx context.Context, criteria models.BookCriteria, fn func(*models.Book) error) error {
+	return r.Repository.Book.Stream(ctx, criteria, fn)
+}
+
 //UpdateBook func
-func (r *InitBookService) UpdateBook(book models.Book) error {
+func (r *InitBookService) UpdateBook(ctx context.Context, book models.Book) error {
 	//start transaction
-	ctx := context.Background()
 	defer dbtrxn.Begin(&ctx)()
 
-	err := r.Repository.Book.Update(ctx, book)
+	err := r.updateBook(ctx, book)
 
 	//transaction commit or rollback if error
 	dbtrxn.Error(ctx)
 
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("book_id", book.ID).Msg("book updated")
+	}
+	return err
+}
+
+//UpsertBook func
+func (r *InitBookService) UpsertBook(ctx context.Context, key models.NaturalKey, book models.Book) (id int64, created bool, err error) {
+	if err = key.Validate(book); err != nil {
+		return 0, false, err
+	}
+
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	if err = r.resolveAuthors(ctx, &book); err == nil {
+		if id, created, err = r.Repository.Book.Upsert(ctx, key, book); err == nil {
+			book.ID = id
+			err = r.link(ctx, book)
+		}
+	}
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("book_id", id).Bool("created", created).Str("key", string(key)).Msg("book upserted")
+	}
+	return id, created, err
+}
+
+//PatchBook func
+func (r *InitBookService) PatchBook(ctx context.Context, book models.Book, columns []string) error {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	err := r.patchBook(ctx, book, columns)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("book_id", book.ID).Strs("columns", columns).Msg("book patched")
+	}
 	return err
 }
 
 //DeleteBook func
-func (r *InitBookService) DeleteBook(id int64) error {
+func (r *InitBookService) DeleteBook(ctx context.Context, id int64, version int64) error {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	err := r.Repository.Book.Delete(ctx, id, version)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("book_id", id).Msg("book deleted")
+	}
+	return err
+}
+
+//RestoreBook func
+func (r *InitBookService) RestoreBook(ctx context.Context, id int64) error {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	err := r.Repository.Book.Restore(ctx, id)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("book_id", id).Msg("book restored")
+	}
+	return err
+}
+
+//PurgeBook func
+func (r *InitBookService) PurgeBook(ctx context.Context, retention time.Duration) (int64, error) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	purged, err := r.Repository.Book.Purge(ctx, time.Now().Add(-retention))
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	if err == nil {
+		zerolog.Ctx(ctx).Info().Int64("count", purged).Dur("retention", retention).Msg("books purged")
+	}
+	return purged, err
+}
+
+//AttachBookTags func
+func (r *InitBookService) AttachBookTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	if err := r.changeTags(ctx, id, tags, r.Repository.Book.AddTags); err != nil {
+		return nil, err
+	}
+	return r.bookTags(ctx, id)
+}
+
+//DetachBookTags func
+func (r *InitBookService) DetachBookTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	if err := r.changeTags(ctx, id, tags, r.Repository.Book.RemoveTags); err != nil {
+		return nil, err
+	}
+	return r.bookTags(ctx, id)
+}
+
+// changeTags add or remove tags of the book in its own transaction so the tags is listed after it is committed
+func (r *InitBookService) changeTags(ctx context.Context, id int64, tags []string, change func(context.Context, int64, []string) error) error {
 	//start transaction
-	ctx := context.Background()
 	defer dbtrxn.Begin(&ctx)()
 
-	err := r.Repository.Book.Delete(ctx, id)
+	err := change(ctx, id, tags)
 
 	//transaction commit or rollback if error
 	dbtrxn.Error(ctx)
 
 	return err
 }
+
+//BookHistory func
+func (r *InitBookService) BookHistory(ctx context.Context, id int64, criteria models.BookCriteria) ([]*models.BookAudit, int64, error) {
+	return r.Repository.Book.History(ctx, id, criteria)
+}
+
+//RevertBook func
+func (r *InitBookService) RevertBook(ctx context.Context, id int64, revision int64, version int64) (*models.Book, error) {
+	audit, err := r.Repository.Book.FindRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if audit == nil {
+		return nil, models.ErrRevisionNotFound
+	}
+
+	book, err := audit.Book()
+	if err != nil {
+		return nil, err
+	}
+	book.Version = version
+
+	if err = r.revert(ctx, *book); err != nil {
+		return nil, err
+	}
+	zerolog.Ctx(ctx).Info().Int64("book_id", id).Int64("revision", revision).Msg("book reverted")
+	return r.GetBook(ctx, id)
+}
+
+// revert restore the book in its own transaction so the reverted book and its version is read after it is committed
+func (r *InitBookService) revert(ctx context.Context, book models.Book) error {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	err := r.revertBook(ctx, book)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return err
+}
+
+func (r *InitBookService) bookTags(ctx context.Context, id int64) ([]string, error) {
+	tags, err := r.Repository.Book.ListTags(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	return tags[id], nil
+}
+
+// insertBook insert the book and link its authors and categories
+func (r *InitBookService) insertBook(ctx context.Context, book models.Book) (id int64, err error) {
+	if err = r.resolveAuthors(ctx, &book); err != nil {
+		return
+	}
+	if id, err = r.Repository.Book.Insert(ctx, book); err != nil {
+		return
+	}
+	book.ID = id
+	err = r.link(ctx, book)
+	return
+}
+
+// importBatch insert the books in single query and link their authors; the ids is filled in the same order with the books
+func (r *InitBookService) importBatch(ctx context.Context, books []models.Book, ids []int64) (err error) {
+	// NOTE: copy so the books given by caller is not linked to the authors which is rolled back
+	resolved := make([]models.Book, len(books))
+	for i, book := range books {
+		if err = r.resolveAuthors(ctx, &book); err != nil {
+			return
+		}
+		resolved[i] = book
+	}
+
+	inserted, err := r.Repository.Book.InsertBatch(ctx, resolved)
+	if err != nil {
+		return
+	}
+	for i, id := range inserted {
+		resolved[i].ID = id
+		if err = r.link(ctx, resolved[i]); err != nil {
+			return
+		}
+	}
+
+	copy(ids, inserted)
+	return nil
+}
+
+// updateBook update the book and replace its authors and categories
+func (r *InitBookService) updateBook(ctx context.Context, book models.Book) (err error) {
+	if err = r.resolveAuthors(ctx, &book); err != nil {
+		return
+	}
+	if err = r.Repository.Book.Update(ctx, book); err != nil {
+		return
+	}
+	return r.link(ctx, book)
+}
+
+// revertBook restore the book to the revision and relink its authors like updateBook so author name and linked authors is agreed
+func (r *InitBookService) revertBook(ctx context.Context, book models.Book) (err error) {
+	if err = r.resolveAuthors(ctx, &book); err != nil {
+		return
+	}
+	if err = r.Repository.Book.Revert(ctx, book); err != nil {
+		return
+	}
+	return r.link(ctx, book)
+}
+
+// patchBook update the columns of the book; the book is linked to the author with the patched name
+func (r *InitBookService) patchBook(ctx context.Context, book models.Book, columns []string) (err error) {
+	var relink bool
+	for _, column := range columns {
+		relink = relink || column == "author"
+	}
+	if relink {
+		if err = r.resolveAuthors(ctx, &book); err != nil {
+			return
+		}
+	}
+	if err = r.Repository.Book.Patch(ctx, book, columns); err != nil || !relink {
+		return
+	}
+	return r.Repository.Author.SetBookAuthors(ctx, book.ID, book.AuthorIDs)
+}
+
+// resolveAuthors find the authors by the ids or by the author name when no id is given; author name of the book is derived from the found authors
+func (r *InitBookService) resolveAuthors(ctx context.Context, book *models.Book) (err error) {
+	var authors []*authormodels.Author
+	if len(book.AuthorIDs) > 0 {
+		authors, err = r.Repository.Author.FindByIDs(ctx, book.AuthorIDs)
+	} else {
+		authors, err = r.authorsByName(ctx, book.ID, book.Author)
+	}
+	if err != nil {
+		return err
+	}
+
+	book.AuthorIDs = make([]int64, len(authors))
+	for i, author := range authors {
+		book.AuthorIDs[i] = author.ID
+	}
+	book.Author = authormodels.JoinNames(authors)
+	return nil
+}
+
+// authorsByName return current authors of the book when the name is not changed; otherwise the author with the name which is created when not exist
+func (r *InitBookService) authorsByName(ctx context.Context, bookID int64, name string) ([]*authormodels.Author, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, authormodels.ErrAuthorNotFound
+	}
+
+	if bookID > 0 {
+		current, err := r.Repository.Author.ListByBooks(ctx, []int64{bookID})
+		if err != nil {
+			return nil, err
+		}
+		if authors := current[bookID]; len(authors) > 0 && authormodels.JoinNames(authors) == name {
+			return authors, nil
+		}
+	}
+
+	author, err := r.Repository.Author.FindOrCreate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return []*authormodels.Author{author}, nil
+}
+
+func (r *InitBookService) link(ctx context.Context, book models.Book) (err error) {
+	if err = r.Repository.Author.SetBookAuthors(ctx, book.ID, book.AuthorIDs); err != nil {
+		return
+	}
+	if book.CategoryIDs != nil {
+		err = r.Repository.Category.SetBookCategories(ctx, book.ID, book.CategoryIDs)
+	}
+	return
+}
+
+// embed fetch authors, categories and tags of the books which is expanded by the criteria; single query for each relation
+func (r *InitBookService) embed(ctx context.Context, criteria models.BookCriteria, books ...*models.Book) (err error) {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	var (
+		authors    map[int64][]*authormodels.Author
+		categories map[int64][]*categorymodels.Category
+		tags       map[int64][]string
+	)
+	if criteria.Expands(models.RelationAuthors) {
+		if authors, err = r.Repository.Author.ListByBooks(ctx, ids); err != nil {
+			return err
+		}
+	}
+	if criteria.Expands(models.RelationCategories) {
+		if categories, err = r.Repository.Category.ListByBooks(ctx, ids); err != nil {
+			return err
+		}
+	}
+	if criteria.Expands(models.RelationTags) {
+		if tags, err = r.Repository.Book.ListTags(ctx, ids); err != nil {
+			return err
+		}
+	}
+
+	for _, book := range books {
+		book.Authors = authors[book.ID]
+		book.Categories = categories[book.ID]
+		book.Tags = tags[book.ID]
+	}
+	return nil
+}
@@ -8,10 +8,12 @@ import (
 	"github.com/bxcodec/faker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
 	"github.com/typical-go/typical-rest-server/app/book/mocks"
 	"github.com/typical-go/typical-rest-server/app/book/models"
 	"github.com/typical-go/typical-rest-server/app/book/repository"
 	"github.com/typical-go/typical-rest-server/app/book/service"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
 )
 
 var BookID int64
@@ -25,33 +27,33 @@ func TestBookControllerGetBook(t *testing.T) {
 	id := int(1)
 
 	t.Run("when success", func(t *testing.T) {
-		mockRepository.On("Find", int64(id)).Return(mockBook, nil)
+		mockRepository.On("Find", mock.Anything, int64(id)).Return(mockBook, nil)
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		book, err := s.GetBook(mockBook.ID)
+		book, err := s.GetBook(context.Background(), mockBook.ID)
 		assert.NoError(t, err)
 		assert.NotNil(t, book)
 
 		tt := new(testing.T)
 		// assert.False(t, mockRepository.AssertExpectations(tt))
-		mockRepository.Find(int64(id))
+		mockRepository.Find(context.TODO(), int64(id))
 		assert.True(t, mockRepository.AssertExpectations(tt))
 	})
 
 	t.Run("when error", func(t *testing.T) {
-		mockRepository.On("Find", int64(id)).Return(nil, errors.New("Unexpected"))
+		mockRepository.On("Find", mock.Anything, int64(id)).Return(nil, errors.New("Unexpected"))
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		book, err := s.GetBook(0)
+		book, err := s.GetBook(context.Background(), 0)
 		err = errors.New("Unexpected")
 		assert.Error(t, err)
 		assert.Nil(t, book)
 
 		tt := new(testing.T)
 		assert.False(t, mockRepository.AssertExpectations(tt))
-		mockRepository.Find(int64(id))
+		mockRepository.Find(context.TODO(), int64(id))
 		// assert.True(t, mockRepository.AssertExpectations(tt))
 	})
 }
@@ -67,26 +69,26 @@ func TestBookControllerListBook(t *testing.T) {
 	}
 
 	t.Run("when success", func(t *testing.T) {
-		mockRepository.On("List", mock.Anything).Return(mockListBook, nil)
+		mockRepository.On("List", mock.Anything, mock.Anything).Return(mockListBook, nil)
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		book, err := s.ListBook()
+		book, _, err := s.ListBook(context.Background(), models.BookCriteria{})
 		assert.NoError(t, err)
 		assert.NotNil(t, book)
 
 		tt := new(testing.T)
 		// assert.False(t, mockRepository.AssertExpectations(tt))
-		mockRepository.List()
+		mockRepository.List(context.TODO(), models.BookCriteria{})
 		assert.True(t, mockRepository.AssertExpectations(tt))
 	})
 
 	t.Run("when error", func(t *testing.T) {
-		mockRepository.On("List", mock.Anything).Return(nil, errors.New("Unexpected"))
+		mockRepository.On("List", mock.Anything, mock.Anything).Return(nil, errors.New("Unexpected"))
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		books, err := s.ListBook()
+		books, _, err := s.ListBook(context.Background(), models.BookCriteria{})
 		books = nil
 		err = errors.New("Unexpected")
 		assert.Error(t, err)
@@ -94,7 +96,7 @@ func TestBookControllerListBook(t *testing.T) {
 
 		tt := new(testing.T)
 		assert.False(t, mockRepository.AssertExpectations(tt))
-		mockRepository.List()
+		mockRepository.List(context.TODO(), models.BookCriteria{})
 		// assert.True(t, mockRepository.AssertExpectations(tt))
 	})
 }
@@ -110,9 +112,9 @@ func TestBookControllerCreateBook(t *testing.T) {
 	t.Run("when success", func(t *testing.T) {
 		mockRepository.On("Insert", mock.Anything, mock.AnythingOfType("*models.Book")).Return(mockBook.ID, nil)
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		bookID, err := s.CreateBook(mockBook)
+		bookID, err := s.CreateBook(context.Background(), mockBook)
 		assert.NoError(t, err)
 		assert.NotNil(t, bookID)
 		BookID = bookID
@@ -137,9 +139,9 @@ func TestBookControllerUpdateBook(t *testing.T) {
 	t.Run("when success", func(t *testing.T) {
 		mockRepository.On("Update", mock.Anything, mock.AnythingOfType("*models.Book")).Return(nil)
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		err := s.UpdateBook(mockBook)
+		err := s.UpdateBook(context.Background(), mockBook)
 		assert.NoError(t, err)
 
 		tt := new(testing.T)
@@ -157,16 +159,16 @@ func TestBookControllerDeleteBook(t *testing.T) {
 	id := int(BookID)
 
 	t.Run("when success", func(t *testing.T) {
-		mockRepository.On("Delete", mock.Anything, int64(id)).Return(nil)
+		mockRepository.On("Delete", mock.Anything, int64(id), int64(0)).Return(nil)
 
-		s := service.NewBookService(bookRepository)
+		s := service.NewBookService(bookRepository, authorrepo.NewAuthorRepository(conn), categoryrepo.NewCategoryRepository(conn))
 
-		err := s.DeleteBook(int64(id))
+		err := s.DeleteBook(context.Background(), int64(id), 0)
 		assert.NoError(t, err)
 
 		tt := new(testing.T)
 		// assert.False(t, mockRepository.AssertExpectations(tt))
-		mockRepository.Delete(context.TODO(), int64(id))
+		mockRepository.Delete(context.TODO(), int64(id), 0)
 		assert.True(t, mockRepository.AssertExpectations(tt))
 	})
 }
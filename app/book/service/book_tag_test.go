@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	authorrepo "github.com/typical-go/typical-rest-server/app/author/repository"
+	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/book/service"
+	categoryrepo "github.com/typical-go/typical-rest-server/app/category/repository"
+)
+
+func TestBookServiceBookTags(t *testing.T) {
+	listSQL := regexp.QuoteMeta(`SELECT book_id, tag FROM book_tags WHERE book_id IN ($1) ORDER BY book_id, tag`)
+
+	t.Run("attach", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO book_tags (book_id,tag) VALUES ($1,$2) ON CONFLICT DO NOTHING`)).
+			WithArgs(7, "fantasy").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(listSQL).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}).AddRow(7, "classic").AddRow(7, "fantasy"))
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		tags, err := s.AttachBookTags(context.Background(), 7, []string{"fantasy"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"classic", "fantasy"}, tags)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("detach", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM book_tags WHERE book_id = $1 AND tag IN ($2)`)).
+			WithArgs(7, "fantasy").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(listSQL).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"book_id", "tag"}).AddRow(7, "classic"))
+
+		s := service.NewBookService(repository.NewBookRepository(db), authorrepo.NewAuthorRepository(db), categoryrepo.NewCategoryRepository(db))
+		tags, err := s.DetachBookTags(context.Background(), 7, []string{"fantasy"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"classic"}, tags)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
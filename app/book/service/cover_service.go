@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/helper/imagekit"
+	"github.com/typical-go/typical-rest-server/config"
+	"github.com/typical-go/typical-rest-server/pkg/storage"
+)
+
+//CoverService interface
+type CoverService interface {
+	UploadCover(ctx context.Context, id int64, r io.Reader) error
+	GetCover(ctx context.Context, id int64, size string) (*storage.Object, error)
+}
+
+//InitCoverService struct
+type InitCoverService struct {
+	Repository *InitCoverRepositoryInterface
+	Storage    storage.Storage
+	MaxSize    int64
+}
+
+//InitCoverRepositoryInterface struct
+type InitCoverRepositoryInterface struct {
+	Book repository.BookRepository
+}
+
+// NewCoverService return new instance of CoverService
+func NewCoverService(bookRepository repository.BookRepository, store storage.Storage, cfg config.AppConfig) CoverService {
+	return &InitCoverService{
+		Repository: &InitCoverRepositoryInterface{
+			Book: bookRepository,
+		},
+		Storage: store,
+		MaxSize: cfg.CoverMaxSize,
+	}
+}
+
+//UploadCover func
+func (r *InitCoverService) UploadCover(ctx context.Context, id int64, reader io.Reader) error {
+	if err := r.checkBook(ctx, id); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(reader, r.MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > r.MaxSize {
+		return models.ErrCoverTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return models.ErrUnsupportedCover
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s", models.ErrUnsupportedCover, err.Error())
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return models.ErrUnsupportedCover
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > models.CoverMaxPixels {
+		return models.ErrCoverTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s", models.ErrUnsupportedCover, err.Error())
+	}
+
+	// NOTE: thumbnail is encoded with the same format as the original to keep PNG transparency
+	for size, width := range models.CoverThumbnails {
+		var buf bytes.Buffer
+		thumbnail := imagekit.Thumbnail(img, width)
+		if contentType == "image/png" {
+			err = png.Encode(&buf, thumbnail)
+		} else {
+			err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return err
+		}
+		if err = r.Storage.Put(models.CoverKey(id, size), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return r.Storage.Put(models.CoverKey(id, models.CoverOriginal), data)
+}
+
+//GetCover func
+func (r *InitCoverService) GetCover(ctx context.Context, id int64, size string) (*storage.Object, error) {
+	if size == "" {
+		size = models.CoverOriginal
+	}
+	if _, ok := models.CoverThumbnails[size]; !ok && size != models.CoverOriginal {
+		return nil, models.ErrInvalidCoverSize
+	}
+
+	if err := r.checkBook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	object, err := r.Storage.Get(models.CoverKey(id, size))
+	if err == storage.ErrNotFound {
+		return nil, models.ErrCoverNotFound
+	}
+	return object, err
+}
+
+// checkBook return error when book is not exist or already deleted
+func (r *InitCoverService) checkBook(ctx context.Context, id int64) error {
+	book, err := r.Repository.Book.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if book == nil {
+		return models.ErrBookNotFound
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/book/models"
+	"github.com/typical-go/typical-rest-server/app/book/repository"
+	"github.com/typical-go/typical-rest-server/app/book/service"
+	"github.com/typical-go/typical-rest-server/config"
+	"github.com/typical-go/typical-rest-server/pkg/storage"
+)
+
+func TestCoverService(t *testing.T) {
+	findSQL := regexp.QuoteMeta(`SELECT id, title, author, isbn, published_year, publisher, language, page_count, version, updated_at, created_at, deleted_at FROM books WHERE deleted_at IS NULL AND id = $1`)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	root, err := ioutil.TempDir("", "cover")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	store := storage.NewLocal(root)
+	s := service.NewCoverService(repository.NewBookRepository(db), store, config.AppConfig{CoverMaxSize: 1 << 20})
+
+	expectBook := func(id int64) {
+		now := time.Now()
+		mock.ExpectQuery(findSQL).WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(repository.BookColumns).
+				AddRow(id, "some-title", "some-author", "", 0, "", "", 0, 1, now, now, nil))
+	}
+
+	var cover bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 600, 900))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	require.NoError(t, png.Encode(&cover, img))
+
+	t.Run("upload to missing book", func(t *testing.T) {
+		mock.ExpectQuery(findSQL).WithArgs(2).WillReturnRows(sqlmock.NewRows(repository.BookColumns))
+		err := s.UploadCover(context.TODO(), 2, bytes.NewReader(cover.Bytes()))
+		require.Equal(t, models.ErrBookNotFound, err)
+	})
+
+	t.Run("upload unsupported file", func(t *testing.T) {
+		expectBook(1)
+		err := s.UploadCover(context.TODO(), 1, strings.NewReader("some-text"))
+		require.Equal(t, models.ErrUnsupportedCover, err)
+	})
+
+	t.Run("upload too large file", func(t *testing.T) {
+		expectBook(1)
+		err := s.UploadCover(context.TODO(), 1, bytes.NewReader(make([]byte, 1<<20+1)))
+		require.Equal(t, models.ErrCoverTooLarge, err)
+	})
+
+	t.Run("upload huge dimension", func(t *testing.T) {
+		// NOTE: PNG header declaring 100000x100000 image without its pixel data
+		ihdr := make([]byte, 17)
+		copy(ihdr, "IHDR")
+		binary.BigEndian.PutUint32(ihdr[4:], 100000)
+		binary.BigEndian.PutUint32(ihdr[8:], 100000)
+		ihdr[12], ihdr[13] = 8, 6
+		bomb := bytes.NewBufferString("\x89PNG\r\n\x1a\n")
+		binary.Write(bomb, binary.BigEndian, uint32(13))
+		bomb.Write(ihdr)
+		binary.Write(bomb, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+
+		expectBook(1)
+		err := s.UploadCover(context.TODO(), 1, bomb)
+		require.Equal(t, models.ErrCoverTooLarge, err)
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		expectBook(1)
+		require.NoError(t, s.UploadCover(context.TODO(), 1, bytes.NewReader(cover.Bytes())))
+
+		original, err := store.Get("book/1/cover/original")
+		require.NoError(t, err)
+		require.Equal(t, cover.Bytes(), original.Data)
+
+		for size, width := range models.CoverThumbnails {
+			object, err := store.Get("book/1/cover/" + size)
+			require.NoError(t, err)
+			thumbnail, err := png.Decode(bytes.NewReader(object.Data))
+			require.NoError(t, err)
+			require.Equal(t, image.Rect(0, 0, width, width*3/2), thumbnail.Bounds())
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		expectBook(1)
+		object, err := s.GetCover(context.TODO(), 1, "small")
+		require.NoError(t, err)
+		require.NotEmpty(t, object.ETag)
+	})
+
+	t.Run("get invalid size", func(t *testing.T) {
+		_, err := s.GetCover(context.TODO(), 1, "huge")
+		require.Equal(t, models.ErrInvalidCoverSize, err)
+	})
+
+	t.Run("get missing cover", func(t *testing.T) {
+		expectBook(3)
+		_, err := s.GetCover(context.TODO(), 3, "")
+		require.Equal(t, models.ErrCoverNotFound, err)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/app/category/service"
+	"github.com/typical-go/typical-rest-server/app/helper/strkit"
+)
+
+// CategoryController handle input related to Category
+type CategoryController interface {
+	base.BaseCRUDController
+}
+
+//InitCategoryController struct
+type InitCategoryController struct {
+	Service *InitCategoryServiceInterface
+}
+
+//InitCategoryServiceInterface struct
+type InitCategoryServiceInterface struct {
+	Category service.CategoryService
+}
+
+// NewCategoryController return new instance of category controller
+func NewCategoryController(categoryService service.CategoryService) CategoryController {
+	return &InitCategoryController{
+		Service: &InitCategoryServiceInterface{
+			Category: categoryService,
+		},
+	}
+}
+
+//Get func
+func (c *InitCategoryController) Get(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	category, err := c.Service.Category.GetCategory(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if category == nil {
+		return notFound(id)
+	}
+
+	return base.Render(ctx, http.StatusOK, category)
+}
+
+//List func
+func (c *InitCategoryController) List(ctx echo.Context) error {
+	criteria := models.CategoryCriteria{Root: ctx.QueryParam("root") == "true"}
+	if s := ctx.QueryParam("parent_id"); s != "" {
+		parentID, err := strkit.ToInt64(s)
+		if err != nil {
+			return invalidMessage(err)
+		}
+		criteria.ParentID = parentID
+	}
+
+	categories, err := c.Service.Category.ListCategory(ctx.Request().Context(), criteria)
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data: categories,
+		Meta: map[string]int{"total": len(categories)},
+	})
+}
+
+//Create func
+func (c *InitCategoryController) Create(ctx echo.Context) (err error) {
+	var category models.Category
+	err = ctx.Bind(&category)
+	if err != nil {
+		return err
+	}
+
+	err = category.Validate()
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	result, err := c.Service.Category.CreateCategory(ctx.Request().Context(), category)
+	if errors.Is(err, models.ErrDuplicateName) {
+		return conflict(err)
+	}
+	if errors.Is(err, models.ErrParentNotFound) {
+		return invalidMessage(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return insertSuccess(ctx, result)
+}
+
+//Update func
+func (c *InitCategoryController) Update(ctx echo.Context) (err error) {
+	var category models.Category
+	err = ctx.Bind(&category)
+	if err != nil {
+		return err
+	}
+
+	if category.ID <= 0 {
+		return invalidID(err)
+	}
+
+	err = category.Validate()
+	if err != nil {
+		return invalidMessage(err)
+	}
+
+	err = c.Service.Category.UpdateCategory(ctx.Request().Context(), category)
+	if errors.Is(err, models.ErrDuplicateName) || errors.Is(err, models.ErrCategoryCycle) {
+		return conflict(err)
+	}
+	if errors.Is(err, models.ErrParentNotFound) {
+		return invalidMessage(err)
+	}
+	if errors.Is(err, models.ErrCategoryNotFound) {
+		return notFound(category.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": "Update success"})
+}
+
+//Delete func
+func (c *InitCategoryController) Delete(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return invalidID(err)
+	}
+
+	err = c.Service.Category.DeleteCategory(ctx.Request().Context(), id)
+	if errors.Is(err, models.ErrCategoryInUse) {
+		return conflict(err)
+	}
+	if errors.Is(err, models.ErrCategoryNotFound) {
+		return notFound(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": fmt.Sprintf("Delete #%d done", id)})
+}
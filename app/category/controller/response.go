@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/base"
+)
+
+func invalidMessage(err error) error {
+	return base.NewValidationError(err)
+}
+
+func invalidID(err error) error {
+	return base.InvalidID(err)
+}
+
+func insertSuccess(ctx echo.Context, lastInsertID int64) error {
+	return base.InsertSuccess(ctx, lastInsertID)
+}
+
+func notFound(id int64) error {
+	return base.NewNotFoundError("category #%d not found", id)
+}
+
+func conflict(err error) error {
+	return base.NewConflictError(err)
+}
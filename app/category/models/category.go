@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+// Error of category operation
+var (
+	// ErrDuplicateName returned when sibling category already has the same name
+	ErrDuplicateName = errors.New("category name already exist")
+	// ErrCategoryNotFound returned when category or linked category is not exist
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrParentNotFound returned when parent category is not exist
+	ErrParentNotFound = errors.New("parent category not found")
+	// ErrCategoryCycle returned when category is moved under itself or its descendant
+	ErrCategoryCycle = errors.New("category can't be moved under itself or its descendant")
+	// ErrCategoryInUse returned when deleted category still has child categories
+	ErrCategoryInUse = errors.New("category still has child categories")
+)
+
+// Category represented database model; root category has no parent
+type Category struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	ParentID  *int64    `json:"parent_id"`
+	UpdatedAt time.Time `json:"-"`
+	CreatedAt time.Time `json:"-"`
+}
+
+//ScanCategory func
+func ScanCategory(rows *sql.Rows) (*Category, error) {
+	var category Category
+	err := rows.Scan(category.Fields()...)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Fields return pointer of category fields as scan destination in the same order with the category columns
+func (c *Category) Fields() []interface{} {
+	return []interface{}{&c.ID, &c.Name, &c.ParentID, &c.UpdatedAt, &c.CreatedAt}
+}
+
+// Validate category
+func (c *Category) Validate() error {
+	return validkit.Struct(c)
+}
@@ -0,0 +1,8 @@
+package models
+
+// CategoryCriteria to filter category listing
+type CategoryCriteria struct {
+	// ParentID list children of the category; Root list categories without parent
+	ParentID int64
+	Root     bool
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategory_Validate(t *testing.T) {
+	category := Category{}
+	err := category.Validate()
+	require.EqualError(t, err, `Key: 'Category.name' Error:Field validation for 'name' failed on the 'required' tag`)
+}
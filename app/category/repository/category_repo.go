@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
+)
+
+// CategoryRepository to get category data from database
+type CategoryRepository interface {
+	Find(ctx context.Context, id int64) (*models.Category, error)
+	List(ctx context.Context, criteria models.CategoryCriteria) ([]*models.Category, error)
+	LockTree(ctx context.Context) error
+	Descendants(ctx context.Context, id int64) ([]int64, error)
+	ListByBooks(ctx context.Context, bookIDs []int64) (map[int64][]*models.Category, error)
+	Insert(ctx context.Context, category models.Category) (lastInsertID int64, err error)
+	Update(ctx context.Context, category models.Category) error
+	Delete(ctx context.Context, id int64) error
+	SetBookCategories(ctx context.Context, bookID int64, categoryIDs []int64) error
+}
+
+//InitCategoryRepository struct
+type InitCategoryRepository struct {
+	conn *sql.DB
+}
+
+// NewCategoryRepository return new instance of CategoryRepository
+func NewCategoryRepository(conn *sql.DB) CategoryRepository {
+	return &InitCategoryRepository{
+		conn: conn,
+	}
+}
+
+//Find func
+func (r *InitCategoryRepository) Find(ctx context.Context, id int64) (category *models.Category, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(CategoryColumns...).
+		From(categoryTable).
+		Where(sq.Eq{idColumn: id})
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return category, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		category, err = models.ScanCategory(rows)
+	}
+
+	return category, err
+}
+
+//List func
+func (r *InitCategoryRepository) List(ctx context.Context, criteria models.CategoryCriteria) (list []*models.Category, err error) {
+	list = make([]*models.Category, 0)
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(CategoryColumns...).From(categoryTable).OrderBy(categoryNameColumn+" ASC", idColumn+" ASC")
+	switch {
+	case criteria.Root:
+		builder = builder.Where(sq.Eq{categoryParentIDColumn: nil})
+	case criteria.ParentID > 0:
+		builder = builder.Where(sq.Eq{categoryParentIDColumn: criteria.ParentID})
+	}
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category *models.Category
+		category, err = models.ScanCategory(rows)
+		if err != nil {
+			return
+		}
+		list = append(list, category)
+	}
+
+	return list, rows.Err()
+}
+
+//LockTree func
+func (r *InitCategoryRepository) LockTree(ctx context.Context) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	if _, err = trxn.DB.ExecContext(ctx, lockTreeQuery); err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//Descendants func
+func (r *InitCategoryRepository) Descendants(ctx context.Context, id int64) (ids []int64, err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return ids, err
+	}
+
+	rows, err := trxn.DB.QueryContext(ctx, descendantsQuery, id)
+	if err != nil {
+		return ids, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var descendantID int64
+		if err = rows.Scan(&descendantID); err != nil {
+			return
+		}
+		ids = append(ids, descendantID)
+	}
+
+	return ids, rows.Err()
+}
+
+//ListByBooks func
+func (r *InitCategoryRepository) ListByBooks(ctx context.Context, bookIDs []int64) (categories map[int64][]*models.Category, err error) {
+	categories = make(map[int64][]*models.Category)
+	if len(bookIDs) == 0 {
+		return categories, nil
+	}
+
+	columns := []string{"bc." + bookIDColumn}
+	for _, column := range CategoryColumns {
+		columns = append(columns, "c."+column)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(columns...).
+		From(bookCategoryTable + " bc").
+		Join(fmt.Sprintf("%s c ON c.%s = bc.%s", categoryTable, idColumn, categoryIDColumn)).
+		Where(sq.Eq{"bc." + bookIDColumn: bookIDs}).
+		OrderBy("bc."+bookIDColumn, "c."+categoryNameColumn, "c."+idColumn)
+
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return categories, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookID int64
+		var category models.Category
+		if err = rows.Scan(append([]interface{}{&bookID}, category.Fields()...)...); err != nil {
+			return
+		}
+		categories[bookID] = append(categories[bookID], &category)
+	}
+
+	return categories, rows.Err()
+}
+
+//Insert func
+func (r *InitCategoryRepository) Insert(ctx context.Context, category models.Category) (lastInsertID int64, err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return lastInsertID, err
+	}
+
+	query := sq.Insert(categoryTable).
+		Columns(categoryNameColumn, categoryParentIDColumn).
+		Values(category.Name, category.ParentID).
+		Suffix("RETURNING \"id\"").
+		RunWith(trxn.DB).
+		PlaceholderFormat(sq.Dollar)
+
+	err = categoryError(query.QueryRowContext(ctx).Scan(&lastInsertID))
+	if err != nil {
+		trxn.SetError(err)
+		return lastInsertID, err
+	}
+
+	return lastInsertID, err
+}
+
+//Update func
+func (r *InitCategoryRepository) Update(ctx context.Context, category models.Category) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Update(categoryTable).
+		Set(categoryNameColumn, category.Name).
+		Set(categoryParentIDColumn, category.ParentID).
+		Set(updatedAtColumn, time.Now()).
+		Where(sq.Eq{idColumn: category.ID})
+
+	result, err := builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err = categoryError(err); err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//Delete func
+func (r *InitCategoryRepository) Delete(ctx context.Context, id int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Delete(categoryTable).
+		Where(sq.Eq{idColumn: id})
+
+	result, err := builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err = categoryError(err); err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		// NOTE: parent key is violated on delete only when the category still has children
+		if errors.Is(err, models.ErrParentNotFound) {
+			err = models.ErrCategoryInUse
+		}
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+//SetBookCategories func
+func (r *InitCategoryRepository) SetBookCategories(ctx context.Context, bookID int64, categoryIDs []int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	_, err = psql.Delete(bookCategoryTable).
+		Where(sq.Eq{bookIDColumn: bookID}).
+		RunWith(trxn.DB).
+		ExecContext(ctx)
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	builder := psql.Insert(bookCategoryTable).
+		Columns(bookIDColumn, categoryIDColumn).
+		Suffix("ON CONFLICT DO NOTHING")
+	for _, categoryID := range categoryIDs {
+		builder = builder.Values(bookID, categoryID)
+	}
+
+	_, err = builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err = categoryError(err); err != nil {
+		trxn.SetError(err)
+		return err
+	}
+
+	return err
+}
+
+// categoryError translate constraint violation error into category error
+func categoryError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == uniqueViolation && pqErr.Constraint == categoryNameConstraint:
+		return models.ErrDuplicateName
+	case pqErr.Code == foreignKeyViolation && pqErr.Constraint == categoryParentFKConstraint:
+		return models.ErrParentNotFound
+	case pqErr.Code == foreignKeyViolation && pqErr.Constraint == bookCategoryFKConstraint:
+		return models.ErrCategoryNotFound
+	}
+	return err
+}
+
+// checkAffected return not found error when no category is affected
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected < 1 {
+		return models.ErrCategoryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/app/category/repository"
+)
+
+func TestCategoryRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	categoryRepository := repository.NewCategoryRepository(db)
+	parentID := int64(1)
+
+	t.Run("Insert", func(t *testing.T) {
+		insertSQL := regexp.QuoteMeta(`INSERT INTO categories (name,parent_id) VALUES ($1,$2) RETURNING "id"`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("fantasy", &parentID).
+				WillReturnError(fmt.Errorf("some-insert-error"))
+			_, err := categoryRepository.Insert(context.Background(), models.Category{Name: "fantasy", ParentID: &parentID})
+			require.EqualError(t, err, "some-insert-error")
+		})
+
+		t.Run("duplicate name", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("fantasy", &parentID).
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "categories_name_key"})
+			_, err := categoryRepository.Insert(context.Background(), models.Category{Name: "fantasy", ParentID: &parentID})
+			require.Equal(t, models.ErrDuplicateName, err)
+		})
+
+		t.Run("parent not found", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("fantasy", &parentID).
+				WillReturnError(&pq.Error{Code: "23503", Constraint: "categories_parent_id_fkey"})
+			_, err := categoryRepository.Insert(context.Background(), models.Category{Name: "fantasy", ParentID: &parentID})
+			require.Equal(t, models.ErrParentNotFound, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("fiction", nil).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+			id, err := categoryRepository.Insert(context.Background(), models.Category{Name: "fiction"})
+			require.NoError(t, err)
+			require.Equal(t, int64(99), id)
+		})
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		findSQL := regexp.QuoteMeta(`SELECT id, name, parent_id, updated_at, created_at FROM categories WHERE id = $1`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(findSQL).WithArgs(2).WillReturnError(fmt.Errorf("some-find-error"))
+			_, err := categoryRepository.Find(context.Background(), 2)
+			require.EqualError(t, err, "some-find-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(findSQL).WithArgs(2).
+				WillReturnRows(sqlmock.NewRows(repository.CategoryColumns).AddRow(2, "fantasy", 1, now, now))
+			category, err := categoryRepository.Find(context.Background(), 2)
+			require.NoError(t, err)
+			require.Equal(t, &models.Category{ID: 2, Name: "fantasy", ParentID: &parentID, UpdatedAt: now, CreatedAt: now}, category)
+		})
+	})
+
+	t.Run("List", func(t *testing.T) {
+		t.Run("root", func(t *testing.T) {
+			now := time.Now()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, parent_id, updated_at, created_at FROM categories WHERE parent_id IS NULL ORDER BY name ASC, id ASC`)).
+				WillReturnRows(sqlmock.NewRows(repository.CategoryColumns).AddRow(1, "fiction", nil, now, now))
+			categories, err := categoryRepository.List(context.Background(), models.CategoryCriteria{Root: true})
+			require.NoError(t, err)
+			require.Equal(t, []*models.Category{{ID: 1, Name: "fiction", UpdatedAt: now, CreatedAt: now}}, categories)
+		})
+
+		t.Run("children", func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, parent_id, updated_at, created_at FROM categories WHERE parent_id = $1 ORDER BY name ASC, id ASC`)).
+				WithArgs(1).
+				WillReturnError(fmt.Errorf("some-list-error"))
+			_, err := categoryRepository.List(context.Background(), models.CategoryCriteria{ParentID: 1})
+			require.EqualError(t, err, "some-list-error")
+		})
+	})
+
+	t.Run("Descendants", func(t *testing.T) {
+		descendantsSQL := regexp.QuoteMeta(`WITH RECURSIVE tree AS (`)
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(descendantsSQL).WithArgs(1).WillReturnError(fmt.Errorf("some-descendants-error"))
+			_, err := categoryRepository.Descendants(context.Background(), 1)
+			require.EqualError(t, err, "some-descendants-error")
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(descendantsSQL).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(5))
+			ids, err := categoryRepository.Descendants(context.Background(), 1)
+			require.NoError(t, err)
+			require.Equal(t, []int64{1, 2, 5}, ids)
+		})
+	})
+
+	t.Run("ListByBooks", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT bc.book_id, c.id, c.name, c.parent_id, c.updated_at, c.created_at FROM book_categories bc ` +
+			`JOIN categories c ON c.id = bc.category_id WHERE bc.book_id IN ($1,$2) ORDER BY bc.book_id, c.name, c.id`)).
+			WithArgs(10, 11).
+			WillReturnRows(sqlmock.NewRows(append([]string{"book_id"}, repository.CategoryColumns...)).
+				AddRow(10, 2, "fantasy", 1, now, now))
+		categories, err := categoryRepository.ListByBooks(context.TODO(), []int64{10, 11})
+		require.NoError(t, err)
+		require.Equal(t, map[int64][]*models.Category{
+			10: {{ID: 2, Name: "fantasy", ParentID: &parentID, UpdatedAt: now, CreatedAt: now}},
+		}, categories)
+	})
+
+	t.Run("LockTree", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		err := categoryRepository.LockTree(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updateSQL := regexp.QuoteMeta(`UPDATE categories SET name = $1, parent_id = $2, updated_at = $3 WHERE id = $4`)
+
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("fantasy", &parentID, sqlmock.AnyArg(), 404).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			err := categoryRepository.Update(context.Background(), models.Category{ID: 404, Name: "fantasy", ParentID: &parentID})
+			require.Equal(t, models.ErrCategoryNotFound, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("fantasy", &parentID, sqlmock.AnyArg(), 2).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			err := categoryRepository.Update(context.Background(), models.Category{ID: 2, Name: "fantasy", ParentID: &parentID})
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleteSQL := regexp.QuoteMeta(`DELETE FROM categories WHERE id = $1`)
+
+		t.Run("has children", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(1).
+				WillReturnError(&pq.Error{Code: "23503", Constraint: "categories_parent_id_fkey"})
+			err := categoryRepository.Delete(context.Background(), 1)
+			require.Equal(t, models.ErrCategoryInUse, err)
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(404).WillReturnResult(sqlmock.NewResult(0, 0))
+			err := categoryRepository.Delete(context.Background(), 404)
+			require.Equal(t, models.ErrCategoryNotFound, err)
+		})
+
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(2).WillReturnResult(sqlmock.NewResult(1, 1))
+			err := categoryRepository.Delete(context.Background(), 2)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("SetBookCategories", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM book_categories WHERE book_id = $1`)).WithArgs(10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO book_categories (book_id,category_id) VALUES ($1,$2),($3,$4) ON CONFLICT DO NOTHING`)).
+			WithArgs(10, 2, 10, 3).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		err := categoryRepository.SetBookCategories(context.Background(), 10, []int64{2, 3})
+		require.NoError(t, err)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
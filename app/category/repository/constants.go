@@ -0,0 +1,48 @@
+package repository
+
+// Table Name
+const (
+	categoryTable     = "categories"
+	bookCategoryTable = "book_categories"
+)
+
+// Table Column Names
+const (
+	idColumn        = "id"
+	updatedAtColumn = "updated_at"
+	createdAtColumn = "created_at"
+
+	// Category Table Column Names
+	categoryNameColumn     = "name"
+	categoryParentIDColumn = "parent_id"
+
+	// Book Category Table Column Names
+	bookIDColumn     = "book_id"
+	categoryIDColumn = "category_id"
+)
+
+// Constraint Names
+const (
+	categoryNameConstraint     = "categories_name_key"
+	categoryParentFKConstraint = "categories_parent_id_fkey"
+	bookCategoryFKConstraint   = "book_categories_category_id_fkey"
+
+	// postgres error code when unique or foreign key constraint is violated
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// descendantsQuery select id of the category and all of its descendants
+const descendantsQuery = `WITH RECURSIVE tree AS (
+SELECT id FROM categories WHERE id = $1
+UNION
+SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+) SELECT id FROM tree`
+
+// lockTreeQuery lock the categories against concurrent change until the transaction end; the lock conflict with itself
+const lockTreeQuery = `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`
+
+// Table Columns
+var (
+	CategoryColumns = []string{idColumn, categoryNameColumn, categoryParentIDColumn, updatedAtColumn, createdAtColumn}
+)
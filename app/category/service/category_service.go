@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+
+	"github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/app/category/repository"
+	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
+)
+
+//CategoryService interface
+type CategoryService interface {
+	CreateCategory(ctx context.Context, category models.Category) (int64, error)
+	GetCategory(ctx context.Context, id int64) (*models.Category, error)
+	ListCategory(ctx context.Context, criteria models.CategoryCriteria) ([]*models.Category, error)
+	UpdateCategory(ctx context.Context, category models.Category) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+//InitCategoryService struct
+type InitCategoryService struct {
+	Repository *InitCategoryRepositoryInterface
+}
+
+//InitCategoryRepositoryInterface struct
+type InitCategoryRepositoryInterface struct {
+	Category repository.CategoryRepository
+}
+
+// NewCategoryService return new instance of CategoryService
+func NewCategoryService(categoryRepository repository.CategoryRepository) CategoryService {
+	return &InitCategoryService{
+		Repository: &InitCategoryRepositoryInterface{
+			Category: categoryRepository,
+		},
+	}
+}
+
+//GetCategory func
+func (r *InitCategoryService) GetCategory(ctx context.Context, id int64) (*models.Category, error) {
+	return r.Repository.Category.Find(ctx, id)
+}
+
+//ListCategory func
+func (r *InitCategoryService) ListCategory(ctx context.Context, criteria models.CategoryCriteria) ([]*models.Category, error) {
+	return r.Repository.Category.List(ctx, criteria)
+}
+
+//CreateCategory func
+func (r *InitCategoryService) CreateCategory(ctx context.Context, category models.Category) (int64, error) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	result, err := r.Repository.Category.Insert(ctx, category)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return result, err
+}
+
+//UpdateCategory func
+func (r *InitCategoryService) UpdateCategory(ctx context.Context, category models.Category) error {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	err := r.updateCategory(ctx, category)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return err
+}
+
+// updateCategory update the category after check it is not moved under its descendant; the tree is locked so concurrent move can't make a cycle
+func (r *InitCategoryService) updateCategory(ctx context.Context, category models.Category) error {
+	if category.ParentID != nil {
+		if err := r.Repository.Category.LockTree(ctx); err != nil {
+			return err
+		}
+		descendants, err := r.Repository.Category.Descendants(ctx, category.ID)
+		if err != nil {
+			dbtrxn.SetError(ctx, err)
+			return err
+		}
+		for _, id := range descendants {
+			if id == *category.ParentID {
+				dbtrxn.SetError(ctx, models.ErrCategoryCycle)
+				return models.ErrCategoryCycle
+			}
+		}
+	}
+
+	return r.Repository.Category.Update(ctx, category)
+}
+
+//DeleteCategory func
+func (r *InitCategoryService) DeleteCategory(ctx context.Context, id int64) error {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	err := r.Repository.Category.Delete(ctx, id)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return err
+}
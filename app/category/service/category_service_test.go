@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/category/models"
+	"github.com/typical-go/typical-rest-server/app/category/repository"
+	"github.com/typical-go/typical-rest-server/app/category/service"
+)
+
+func TestCategoryServiceUpdateCategory(t *testing.T) {
+	descendantsSQL := regexp.QuoteMeta(`WITH RECURSIVE tree AS (`)
+	lockSQL := regexp.QuoteMeta(`LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`)
+	updateSQL := regexp.QuoteMeta(`UPDATE categories SET name = $1, parent_id = $2, updated_at = $3 WHERE id = $4`)
+
+	t.Run("moved under its descendant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(descendantsSQL).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(5))
+		mock.ExpectRollback()
+
+		parentID := int64(5)
+		s := service.NewCategoryService(repository.NewCategoryRepository(db))
+		err = s.UpdateCategory(context.Background(), models.Category{ID: 1, Name: "fiction", ParentID: &parentID})
+		require.Equal(t, models.ErrCategoryCycle, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("moved under other category", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		parentID := int64(7)
+		mock.ExpectBegin()
+		mock.ExpectExec(lockSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(descendantsSQL).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectExec(updateSQL).WithArgs("fiction", &parentID, sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		s := service.NewCategoryService(repository.NewCategoryRepository(db))
+		err = s.UpdateCategory(context.Background(), models.Category{ID: 1, Name: "fiction", ParentID: &parentID})
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,184 @@
+package crud
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/strkit"
+)
+
+// Controller handle create read update delete of entity; embed it to override or add route of the entity
+type Controller struct {
+	// Name of the entity in the route and message (e.g. "author")
+	Name    string
+	Table   Table
+	Service Service
+}
+
+// NewController return new instance of controller of the entity
+func NewController(name string, table Table, service Service) *Controller {
+	return &Controller{
+		Name:    name,
+		Table:   table,
+		Service: service,
+	}
+}
+
+//Get func
+func (c *Controller) Get(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return base.InvalidID(err)
+	}
+	entity, err := c.Service.Find(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	if entity == nil {
+		return c.NotFound(id)
+	}
+	return base.Render(ctx, http.StatusOK, entity)
+}
+
+//List func
+func (c *Controller) List(ctx echo.Context) error {
+	criteria := Criteria{
+		Search: make(map[string]string),
+		Page:   1,
+		Size:   DefaultPageSize,
+	}
+	for param, column := range c.Table.Search {
+		if value := ctx.QueryParam(param); value != "" {
+			criteria.Search[column] = value
+		}
+	}
+	var err error
+	if criteria.Page, err = uintQueryParam(ctx, "page", criteria.Page); err != nil || criteria.Page < 1 {
+		return base.NewValidationError(err)
+	}
+	if criteria.Size, err = uintQueryParam(ctx, "size", criteria.Size); err != nil || criteria.Size < 1 || criteria.Size > MaxPageSize {
+		return base.NewValidationError(err)
+	}
+	if criteria.OrderBy, err = c.orderBy(ctx.QueryParam("sort")); err != nil {
+		return base.NewValidationError(err)
+	}
+	list, total, err := c.Service.List(ctx.Request().Context(), criteria)
+	if err != nil {
+		return err
+	}
+	return base.Render(ctx, http.StatusOK, base.ListResponse{
+		Data: list,
+		Meta: base.Pagination{
+			Page:       criteria.Page,
+			Size:       criteria.Size,
+			Total:      total,
+			TotalPages: criteria.TotalPages(total),
+		},
+	})
+}
+
+//Create func
+func (c *Controller) Create(ctx echo.Context) (err error) {
+	entity := c.Table.New()
+	err = ctx.Bind(entity)
+	if err != nil {
+		return err
+	}
+	err = entity.Validate()
+	if err != nil {
+		return base.NewValidationError(err)
+	}
+	result, err := c.Service.Create(ctx.Request().Context(), entity)
+	if c.Table.IsUnique(err) {
+		return base.NewConflictError(err)
+	}
+	if err != nil {
+		return err
+	}
+	return base.InsertSuccess(ctx, result)
+}
+
+//Update func
+func (c *Controller) Update(ctx echo.Context) (err error) {
+	entity := c.Table.New()
+	err = ctx.Bind(entity)
+	if err != nil {
+		return err
+	}
+	if ID(entity) <= 0 {
+		return base.InvalidID(err)
+	}
+	err = entity.Validate()
+	if err != nil {
+		return base.NewValidationError(err)
+	}
+	err = c.Service.Update(ctx.Request().Context(), entity)
+	if c.Table.IsUnique(err) {
+		return base.NewConflictError(err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return c.NotFound(ID(entity))
+	}
+	if err != nil {
+		return err
+	}
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": "Update success"})
+}
+
+//Delete func
+func (c *Controller) Delete(ctx echo.Context) error {
+	id, err := strkit.ToInt64(ctx.Param("id"))
+	if err != nil {
+		return base.InvalidID(err)
+	}
+	err = c.Service.Delete(ctx.Request().Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		return c.NotFound(id)
+	}
+	if err != nil {
+		return err
+	}
+	return base.Render(ctx, http.StatusOK, map[string]string{"message": fmt.Sprintf("Delete #%d done", id)})
+}
+
+// NotFound return error of missing entity
+func (c *Controller) NotFound(id int64) error {
+	return base.NewNotFoundError("%s #%d not found", c.Name, id)
+}
+
+// orderBy parse comma separated sort query parameter; descending when prefixed by "-" (e.g. "-name,id")
+func (c *Controller) orderBy(sort string) (orderBys []string, err error) {
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(name, "-") {
+			name, direction = name[1:], "DESC"
+		}
+		column, ok := c.Table.Sort[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort column: '%s'", name)
+		}
+		orderBys = append(orderBys, column+" "+direction)
+	}
+	return orderBys, nil
+}
+
+func uintQueryParam(ctx echo.Context, name string, defaultValue uint64) (uint64, error) {
+	s := ctx.QueryParam(name)
+	if s == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,141 @@
+package crud_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/crud"
+)
+
+type fakeService struct {
+	entities map[int64]crud.Entity
+	criteria crud.Criteria
+	err      error
+}
+
+func (s *fakeService) Find(ctx context.Context, id int64) (crud.Entity, error) {
+	return s.entities[id], s.err
+}
+
+func (s *fakeService) List(ctx context.Context, criteria crud.Criteria) ([]crud.Entity, int64, error) {
+	s.criteria = criteria
+	list := make([]crud.Entity, 0)
+	for _, entity := range s.entities {
+		list = append(list, entity)
+	}
+	return list, int64(len(list)), s.err
+}
+
+func (s *fakeService) Create(ctx context.Context, entity crud.Entity) (int64, error) {
+	return 99, s.err
+}
+
+func (s *fakeService) Update(ctx context.Context, entity crud.Entity) error {
+	return s.err
+}
+
+func (s *fakeService) Delete(ctx context.Context, id int64) error {
+	return s.err
+}
+
+func TestController(t *testing.T) {
+	e := echo.New()
+	service := &fakeService{entities: map[int64]crud.Entity{
+		1: &publisher{ID: 1, Name: "some-name", Country: "ID"},
+	}}
+	c := crud.NewController("publisher", publisherTable, service)
+	var _ base.BaseCRUDController = c
+
+	t.Run("Get", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("1")
+		require.NoError(t, c.Get(ctx))
+		require.JSONEq(t, `{"id":1,"name":"some-name","country":"ID"}`, rec.Body.String())
+
+		ctx = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("2")
+		require.EqualError(t, c.Get(ctx), "publisher #2 not found")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/?name_like=some&page=2&size=10", nil), rec)
+		require.NoError(t, c.List(ctx))
+		require.Equal(t, crud.Criteria{Search: map[string]string{"name": "some"}, Page: 2, Size: 10}, service.criteria)
+		require.JSONEq(t, `{"data":[{"id":1,"name":"some-name","country":"ID"}],`+
+			`"meta":{"page":2,"size":10,"total":1,"total_pages":1}}`, rec.Body.String())
+
+		ctx = e.NewContext(httptest.NewRequest(http.MethodGet, "/?size=101", nil), httptest.NewRecorder())
+		err := c.List(ctx)
+		appErr, ok := base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, appErr.Status)
+
+		ctx = e.NewContext(httptest.NewRequest(http.MethodGet, "/?sort=-country,name", nil), httptest.NewRecorder())
+		require.NoError(t, c.List(ctx))
+		require.Equal(t, []string{"country DESC", "name ASC"}, service.criteria.OrderBy)
+
+		ctx = e.NewContext(httptest.NewRequest(http.MethodGet, "/?sort=password", nil), httptest.NewRecorder())
+		require.EqualError(t, c.List(ctx), "invalid sort column: 'password'")
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		newRequest := func(body string) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			return req
+		}
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, c.Create(e.NewContext(newRequest(`{"name":"some-name"}`), rec)))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.JSONEq(t, `{"message":"Success insert new record #99","data":99}`, rec.Body.String())
+
+		err := c.Create(e.NewContext(newRequest(`{"country":"ID"}`), httptest.NewRecorder()))
+		appErr, ok := base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, base.ProblemValidation, appErr.Type)
+
+		service.err = errDuplicateName
+		defer func() { service.err = nil }()
+		err = c.Create(e.NewContext(newRequest(`{"name":"some-name"}`), httptest.NewRecorder()))
+		appErr, ok = base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, appErr.Status)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"some-name"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		err := c.Update(e.NewContext(req, httptest.NewRecorder()))
+		require.EqualError(t, err, "invalid id")
+
+		service.err = crud.ErrNotFound
+		defer func() { service.err = nil }()
+		req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"id":404,"name":"some-name"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		err = c.Update(e.NewContext(req, httptest.NewRecorder()))
+		require.EqualError(t, err, "publisher #404 not found")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		service.err = crud.ErrNotFound
+		defer func() { service.err = nil }()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), httptest.NewRecorder())
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("404")
+		err := c.Delete(ctx)
+		appErr, ok := base.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusNotFound, appErr.Status)
+	})
+}
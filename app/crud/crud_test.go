@@ -0,0 +1,37 @@
+package crud_test
+
+import (
+	"errors"
+
+	"github.com/typical-go/typical-rest-server/app/crud"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+var errDuplicateName = errors.New("publisher name already exist")
+
+type publisher struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name" validate:"required"`
+	Country string `json:"country"`
+}
+
+func (p *publisher) Fields() []interface{} {
+	return []interface{}{&p.ID, &p.Name, &p.Country}
+}
+
+func (p *publisher) Validate() error {
+	return validkit.Struct(p)
+}
+
+var publisherTable = crud.Table{
+	Name:     "publishers",
+	Columns:  []string{"id", "name", "country"},
+	Writable: []string{"name", "country"},
+	OrderBy:  []string{"name ASC"},
+	Search:   map[string]string{"name_like": "name", "country": "country"},
+	Sort:     map[string]string{"name": "name", "country": "country"},
+	Unique:   map[string]error{"publishers_name_key": errDuplicateName},
+	New: func() crud.Entity {
+		return &publisher{}
+	},
+}
@@ -0,0 +1,200 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/typical-go/typical-rest-server/app/helper/strkit"
+	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
+)
+
+// postgres error code when unique constraint is violated
+const uniqueViolation = "23505"
+
+// ErrNotFound returned when the entity to update or delete does not exist
+var ErrNotFound = errors.New("entity not found")
+
+// Repository to get entity data from database
+type Repository interface {
+	Find(ctx context.Context, id int64) (Entity, error)
+	List(ctx context.Context, criteria Criteria) ([]Entity, error)
+	Count(ctx context.Context, criteria Criteria) (int64, error)
+	Insert(ctx context.Context, entity Entity) (lastInsertID int64, err error)
+	Update(ctx context.Context, entity Entity) error
+	Delete(ctx context.Context, id int64) error
+}
+
+//InitRepository struct
+type InitRepository struct {
+	conn  *sql.DB
+	table Table
+}
+
+// NewRepository return new instance of Repository of the table
+func NewRepository(conn *sql.DB, table Table) Repository {
+	return &InitRepository{
+		conn:  conn,
+		table: table,
+	}
+}
+
+//Find func
+func (r *InitRepository) Find(ctx context.Context, id int64) (entity Entity, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select(r.table.Columns...).
+		From(r.table.Name).
+		Where(sq.Eq{r.table.PrimaryKey(): id})
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return entity, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		entity = r.table.New()
+		if err = rows.Scan(entity.Fields()...); err != nil {
+			return nil, err
+		}
+	}
+	return entity, rows.Err()
+}
+
+//List func
+func (r *InitRepository) List(ctx context.Context, criteria Criteria) (list []Entity, err error) {
+	list = make([]Entity, 0)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	orderBys := append(append([]string{}, criteria.OrderBy...), r.table.OrderBy...)
+	builder := psql.Select(r.table.Columns...).From(r.table.Name).OrderBy(orderBys...)
+	if filter := r.filter(criteria); len(filter) > 0 {
+		builder = builder.Where(filter)
+	}
+	if criteria.Size > 0 {
+		builder = builder.Limit(criteria.Size).Offset(criteria.Offset())
+	}
+	rows, err := builder.RunWith(r.conn).QueryContext(ctx)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entity := r.table.New()
+		if err = rows.Scan(entity.Fields()...); err != nil {
+			return
+		}
+		list = append(list, entity)
+	}
+	return list, rows.Err()
+}
+
+//Count func
+func (r *InitRepository) Count(ctx context.Context, criteria Criteria) (total int64, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Select("COUNT(*)").From(r.table.Name)
+	if filter := r.filter(criteria); len(filter) > 0 {
+		builder = builder.Where(filter)
+	}
+	err = builder.RunWith(r.conn).QueryRowContext(ctx).Scan(&total)
+	return total, err
+}
+
+//Insert func
+func (r *InitRepository) Insert(ctx context.Context, entity Entity) (lastInsertID int64, err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return lastInsertID, err
+	}
+	query := sq.Insert(r.table.Name).
+		Columns(r.table.Writable...).
+		Values(r.table.values(entity, r.table.Writable)...).
+		Suffix(fmt.Sprintf("RETURNING %q", r.table.PrimaryKey())).
+		RunWith(trxn.DB).
+		PlaceholderFormat(sq.Dollar)
+	err = r.error(query.QueryRowContext(ctx).Scan(&lastInsertID))
+	if err != nil {
+		trxn.SetError(err)
+		return lastInsertID, err
+	}
+	return lastInsertID, err
+}
+
+//Update func
+func (r *InitRepository) Update(ctx context.Context, entity Entity) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Update(r.table.Name)
+	for i, value := range r.table.values(entity, r.table.Writable) {
+		builder = builder.Set(r.table.Writable[i], value)
+	}
+	if r.table.UpdatedAt != "" {
+		builder = builder.Set(r.table.UpdatedAt, time.Now())
+	}
+	builder = builder.Where(sq.Eq{r.table.PrimaryKey(): ID(entity)})
+	result, err := builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err = r.error(err); err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+	return err
+}
+
+//Delete func
+func (r *InitRepository) Delete(ctx context.Context, id int64) (err error) {
+	trxn, err := dbtrxn.Use(ctx, r.conn)
+	if err != nil {
+		return err
+	}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	builder := psql.Delete(r.table.Name).
+		Where(sq.Eq{r.table.PrimaryKey(): id})
+	result, err := builder.RunWith(trxn.DB).ExecContext(ctx)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		trxn.SetError(err)
+		return err
+	}
+	return err
+}
+
+func (r *InitRepository) filter(criteria Criteria) sq.And {
+	filter := sq.And{}
+	for _, column := range criteria.searchColumns() {
+		filter = append(filter, sq.Expr(column+" ILIKE ?", "%"+strkit.EscapeLike(criteria.Search[column])+"%"))
+	}
+	return filter
+}
+
+// checkAffected return ErrNotFound when no row is changed
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// error translate unique constraint violation into error of the table
+func (r *InitRepository) error(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolation {
+		return err
+	}
+	if uniqueErr, ok := r.table.Unique[pqErr.Constraint]; ok {
+		return uniqueErr
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package crud_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/crud"
+)
+
+func TestRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := crud.NewRepository(db, publisherTable)
+
+	t.Run("Insert", func(t *testing.T) {
+		insertSQL := regexp.QuoteMeta(`INSERT INTO publishers (name,country) VALUES ($1,$2) RETURNING "id"`)
+		t.Run("duplicate name", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-name", "ID").
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "publishers_name_key"})
+			_, err := repo.Insert(context.Background(), &publisher{Name: "some-name", Country: "ID"})
+			require.Equal(t, errDuplicateName, err)
+		})
+		t.Run("other constraint", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-name", "ID").
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "publishers_other_key"})
+			_, err := repo.Insert(context.Background(), &publisher{Name: "some-name", Country: "ID"})
+			require.IsType(t, &pq.Error{}, err)
+		})
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-name", "ID").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+			id, err := repo.Insert(context.Background(), &publisher{Name: "some-name", Country: "ID"})
+			require.NoError(t, err)
+			require.Equal(t, int64(99), id)
+		})
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		findSQL := regexp.QuoteMeta(`SELECT id, name, country FROM publishers WHERE id = $1`)
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectQuery(findSQL).WithArgs(404).WillReturnRows(sqlmock.NewRows(publisherTable.Columns))
+			entity, err := repo.Find(context.Background(), 404)
+			require.NoError(t, err)
+			require.Nil(t, entity)
+		})
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectQuery(findSQL).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows(publisherTable.Columns).AddRow(1, "some-name", "ID"))
+			entity, err := repo.Find(context.Background(), 1)
+			require.NoError(t, err)
+			require.Equal(t, &publisher{ID: 1, Name: "some-name", Country: "ID"}, entity)
+		})
+	})
+
+	t.Run("List", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, country FROM publishers `+
+			`WHERE (country ILIKE $1 AND name ILIKE $2) ORDER BY name ASC LIMIT 20 OFFSET 0`)).
+			WithArgs("%ID%", `%some\_name\%%`).
+			WillReturnRows(sqlmock.NewRows(publisherTable.Columns).
+				AddRow(1, "some-name1", "ID").
+				AddRow(2, "some-name2", "ID"))
+		list, err := repo.List(context.Background(), crud.Criteria{
+			Search: map[string]string{"name": "some_name%", "country": "ID"},
+			Page:   1,
+			Size:   20,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []crud.Entity{
+			&publisher{ID: 1, Name: "some-name1", Country: "ID"},
+			&publisher{ID: 2, Name: "some-name2", Country: "ID"},
+		}, list)
+	})
+
+	t.Run("List with sort", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, country FROM publishers ORDER BY country DESC, name ASC`)).
+			WillReturnRows(sqlmock.NewRows(publisherTable.Columns))
+		list, err := repo.List(context.Background(), crud.Criteria{OrderBy: []string{"country DESC"}})
+		require.NoError(t, err)
+		require.Empty(t, list)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updateSQL := regexp.QuoteMeta(`UPDATE publishers SET name = $1, country = $2 WHERE id = $3`)
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("some-name", "ID", 1).
+				WillReturnError(fmt.Errorf("some-update-error"))
+			err := repo.Update(context.Background(), &publisher{ID: 1, Name: "some-name", Country: "ID"})
+			require.EqualError(t, err, "some-update-error")
+		})
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("some-name", "ID", 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			err := repo.Update(context.Background(), &publisher{ID: 1, Name: "some-name", Country: "ID"})
+			require.NoError(t, err)
+		})
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectExec(updateSQL).WithArgs("some-name", "ID", 404).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			err := repo.Update(context.Background(), &publisher{ID: 404, Name: "some-name", Country: "ID"})
+			require.Equal(t, crud.ErrNotFound, err)
+		})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleteSQL := regexp.QuoteMeta(`DELETE FROM publishers WHERE id = $1`)
+		t.Run("sql success", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			require.NoError(t, repo.Delete(context.Background(), 1))
+		})
+		t.Run("not found", func(t *testing.T) {
+			mock.ExpectExec(deleteSQL).WithArgs(404).WillReturnResult(sqlmock.NewResult(0, 0))
+			require.Equal(t, crud.ErrNotFound, repo.Delete(context.Background(), 404))
+		})
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
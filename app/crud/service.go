@@ -0,0 +1,136 @@
+package crud
+
+import (
+	"context"
+
+	"github.com/typical-go/typical-rest-server/pkg/dbtrxn"
+)
+
+// Service of entity
+type Service interface {
+	Find(ctx context.Context, id int64) (Entity, error)
+	List(ctx context.Context, criteria Criteria) ([]Entity, int64, error)
+	Create(ctx context.Context, entity Entity) (int64, error)
+	Update(ctx context.Context, entity Entity) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// Hooks is called in the same transaction with the change; the change is rolled back when the hook return error
+type Hooks struct {
+	BeforeCreate func(ctx context.Context, entity Entity) error
+	// AfterCreate is called with the entity which primary key is set
+	AfterCreate  func(ctx context.Context, entity Entity) error
+	BeforeUpdate func(ctx context.Context, entity Entity) error
+	AfterUpdate  func(ctx context.Context, entity Entity) error
+	BeforeDelete func(ctx context.Context, id int64) error
+	AfterDelete  func(ctx context.Context, id int64) error
+}
+
+//InitService struct
+type InitService struct {
+	Repository Repository
+	Hooks      Hooks
+}
+
+// NewService return new instance of Service
+func NewService(repository Repository, hooks Hooks) Service {
+	return &InitService{
+		Repository: repository,
+		Hooks:      hooks,
+	}
+}
+
+//Find func
+func (s *InitService) Find(ctx context.Context, id int64) (Entity, error) {
+	return s.Repository.Find(ctx, id)
+}
+
+//List func
+func (s *InitService) List(ctx context.Context, criteria Criteria) ([]Entity, int64, error) {
+	list, err := s.Repository.List(ctx, criteria)
+	if err != nil {
+		return list, 0, err
+	}
+	total, err := s.Repository.Count(ctx, criteria)
+	if err != nil {
+		return list, 0, err
+	}
+	return list, total, err
+}
+
+//Create func
+func (s *InitService) Create(ctx context.Context, entity Entity) (id int64, err error) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	if err = callHook(ctx, s.Hooks.BeforeCreate, entity); err != nil {
+		return
+	}
+	if id, err = s.Repository.Insert(ctx, entity); err != nil {
+		return
+	}
+	SetID(entity, id)
+	err = callHook(ctx, s.Hooks.AfterCreate, entity)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return
+}
+
+//Update func
+func (s *InitService) Update(ctx context.Context, entity Entity) (err error) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	if err = callHook(ctx, s.Hooks.BeforeUpdate, entity); err != nil {
+		return
+	}
+	if err = s.Repository.Update(ctx, entity); err != nil {
+		return
+	}
+	err = callHook(ctx, s.Hooks.AfterUpdate, entity)
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return
+}
+
+//Delete func
+func (s *InitService) Delete(ctx context.Context, id int64) (err error) {
+	//start transaction
+	defer dbtrxn.Begin(&ctx)()
+
+	if s.Hooks.BeforeDelete != nil {
+		if err = s.Hooks.BeforeDelete(ctx, id); err != nil {
+			dbtrxn.SetError(ctx, err)
+			return
+		}
+	}
+	if err = s.Repository.Delete(ctx, id); err != nil {
+		return
+	}
+	if s.Hooks.AfterDelete != nil {
+		if err = s.Hooks.AfterDelete(ctx, id); err != nil {
+			dbtrxn.SetError(ctx, err)
+		}
+	}
+
+	//transaction commit or rollback if error
+	dbtrxn.Error(ctx)
+
+	return
+}
+
+// callHook mark the transaction to be rolled back when the hook return error
+func callHook(ctx context.Context, hook func(context.Context, Entity) error, entity Entity) error {
+	if hook == nil {
+		return nil
+	}
+	err := hook(ctx, entity)
+	if err != nil {
+		dbtrxn.SetError(ctx, err)
+	}
+	return err
+}
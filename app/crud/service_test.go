@@ -0,0 +1,123 @@
+package crud_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/crud"
+)
+
+func TestServiceCreate(t *testing.T) {
+	insertSQL := regexp.QuoteMeta(`INSERT INTO publishers (name,country) VALUES ($1,$2) RETURNING "id"`)
+
+	t.Run("before hook error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		s := crud.NewService(crud.NewRepository(db, publisherTable), crud.Hooks{
+			BeforeCreate: func(ctx context.Context, entity crud.Entity) error {
+				return errors.New("some-hook-error")
+			},
+		})
+		_, err = s.Create(context.Background(), &publisher{Name: "some-name"})
+		require.EqualError(t, err, "some-hook-error")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("after hook error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertSQL).WithArgs("some-name", "ID").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		mock.ExpectRollback()
+
+		s := crud.NewService(crud.NewRepository(db, publisherTable), crud.Hooks{
+			AfterCreate: func(ctx context.Context, entity crud.Entity) error {
+				return errors.New("some-hook-error")
+			},
+		})
+		_, err = s.Create(context.Background(), &publisher{Name: "some-name", Country: "ID"})
+		require.EqualError(t, err, "some-hook-error")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertSQL).WithArgs("SOME-NAME", "ID").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+		mock.ExpectCommit()
+
+		var created crud.Entity
+		s := crud.NewService(crud.NewRepository(db, publisherTable), crud.Hooks{
+			BeforeCreate: func(ctx context.Context, entity crud.Entity) error {
+				entity.(*publisher).Name = "SOME-NAME"
+				return nil
+			},
+			AfterCreate: func(ctx context.Context, entity crud.Entity) error {
+				created = entity
+				return nil
+			},
+		})
+		id, err := s.Create(context.Background(), &publisher{Name: "some-name", Country: "ID"})
+		require.NoError(t, err)
+		require.Equal(t, int64(99), id)
+		require.Equal(t, &publisher{ID: 99, Name: "SOME-NAME", Country: "ID"}, created)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestServiceDelete(t *testing.T) {
+	deleteSQL := regexp.QuoteMeta(`DELETE FROM publishers WHERE id = $1`)
+
+	t.Run("after hook error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		s := crud.NewService(crud.NewRepository(db, publisherTable), crud.Hooks{
+			AfterDelete: func(ctx context.Context, id int64) error {
+				return errors.New("some-hook-error")
+			},
+		})
+		err = s.Delete(context.Background(), 1)
+		require.EqualError(t, err, "some-hook-error")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		var deleted []int64
+		s := crud.NewService(crud.NewRepository(db, publisherTable), crud.Hooks{
+			BeforeDelete: func(ctx context.Context, id int64) error {
+				deleted = append(deleted, id)
+				return nil
+			},
+		})
+		require.NoError(t, s.Delete(context.Background(), 1))
+		require.Equal(t, []int64{1}, deleted)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,128 @@
+// Package crud provide generic create read update delete of entity which is stored in a single table
+package crud
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Default and maximum number of entities in a single page
+const (
+	DefaultPageSize uint64 = 20
+	MaxPageSize     uint64 = 100
+)
+
+// Entity is database model which is mapped by the table
+type Entity interface {
+	// Fields return pointer of entity fields as scan destination in the same order with the table columns
+	Fields() []interface{}
+	Validate() error
+}
+
+// Table map entity into database table
+type Table struct {
+	Name string
+
+	// Columns is selected columns in the same order with the entity fields; the first column is the primary key
+	Columns []string
+
+	// Writable is columns which is set on insert and update
+	Writable []string
+
+	// UpdatedAt is column which is set to current time on update; empty when the table does not track it
+	UpdatedAt string
+
+	// OrderBy of the listing (e.g. "name ASC")
+	OrderBy []string
+
+	// Search map query parameter to column which is filtered by case-insensitive partial match
+	Search map[string]string
+
+	// Sort map name in sort query parameter to column which can be sorted by
+	Sort map[string]string
+
+	// Unique map unique constraint name to error returned when the constraint is violated
+	Unique map[string]error
+
+	// New return empty entity
+	New func() Entity
+}
+
+// Criteria to filter and paginate entity listing
+type Criteria struct {
+	// Search map column to partial value
+	Search map[string]string
+	Page   uint64
+	Size   uint64
+
+	// OrderBy is requested sort which take precedence over order of the table (e.g. "name DESC")
+	OrderBy []string
+}
+
+// Offset of first entity in the page
+func (c Criteria) Offset() uint64 {
+	if c.Page <= 1 {
+		return 0
+	}
+	return (c.Page - 1) * c.Size
+}
+
+// TotalPages return number of pages needed to show total entities
+func (c Criteria) TotalPages(total int64) int64 {
+	if c.Size == 0 {
+		return 0
+	}
+	size := int64(c.Size)
+	return (total + size - 1) / size
+}
+
+// PrimaryKey column of the table
+func (t Table) PrimaryKey() string {
+	return t.Columns[0]
+}
+
+// IsUnique return whether the error is returned because of unique constraint violation
+func (t Table) IsUnique(err error) bool {
+	for _, uniqueErr := range t.Unique {
+		if errors.Is(err, uniqueErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchColumns return searchable columns in sorted order
+func (c Criteria) searchColumns() []string {
+	columns := make([]string, 0, len(c.Search))
+	for column := range c.Search {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// values return value of entity fields which is mapped to the columns
+func (t Table) values(entity Entity, columns []string) []interface{} {
+	fields := entity.Fields()
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		for j := range t.Columns {
+			if t.Columns[j] == column {
+				values[i] = reflect.ValueOf(fields[j]).Elem().Interface()
+				break
+			}
+		}
+	}
+	return values
+}
+
+// ID return primary key of the entity
+func ID(entity Entity) int64 {
+	return reflect.ValueOf(entity.Fields()[0]).Elem().Int()
+}
+
+// SetID set primary key of the entity
+func SetID(entity Entity, id int64) {
+	reflect.ValueOf(entity.Fields()[0]).Elem().SetInt(id)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/rs/zerolog"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+// httpErrorHandler render the error as problem details (RFC 7807) or as errors of JSON:API document when it is accepted
+func httpErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	problem := base.NewProblem(err)
+	problem.Instance = c.Request().URL.Path
+	problem.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	if problem.Status >= http.StatusInternalServerError {
+		zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("unexpected error")
+	} else {
+		problem.Errors = validkit.FieldErrors(err, validkit.Translator(c.Request().Header.Get("Accept-Language")))
+	}
+	if len(problem.Errors) > 0 {
+		// NOTE: detail is the translated message so client can show it as is
+		messages := make([]string, len(problem.Errors))
+		for i, fieldErr := range problem.Errors {
+			messages[i] = fieldErr.Message
+		}
+		problem.Detail = strings.Join(messages, "; ")
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(problem.Status)
+	} else if base.IsJSONAPI(c) {
+		var b []byte
+		if b, err = json.Marshal(base.JSONAPIErrors(problem)); err == nil {
+			err = c.Blob(problem.Status, base.MIMEApplicationJSONAPI, b)
+		}
+	} else {
+		var b []byte
+		if b, err = json.Marshal(problem); err == nil {
+			err = c.Blob(problem.Status, base.MIMEApplicationProblemJSON, b)
+		}
+	}
+	if err != nil {
+		zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("render error response")
+	}
+}
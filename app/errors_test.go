@@ -0,0 +1,131 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/validkit"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: func() string { return "some-request-id" }}))
+	e.GET("/book/:id", func(c echo.Context) error {
+		switch c.Param("id") {
+		case "1":
+			return base.NewNotFoundError("book #%d not found", 1)
+		case "2":
+			return base.NewConflictError(errors.New("isbn already exist"))
+		case "4":
+			return base.NewValidationError(validkit.Struct(struct {
+				Title string `json:"title" validate:"required"`
+			}{}))
+		}
+		return errors.New("some-database-error")
+	})
+
+	testcases := []struct {
+		path           string
+		acceptLanguage string
+		status         int
+		expected       string
+	}{
+		{
+			path:   "/book/1",
+			status: http.StatusNotFound,
+			expected: `{"type":"urn:problem-type:not-found","title":"Resource Not Found","status":404,` +
+				`"detail":"book #1 not found","instance":"/book/1","request_id":"some-request-id"}`,
+		},
+		{
+			path:   "/book/2",
+			status: http.StatusConflict,
+			expected: `{"type":"urn:problem-type:conflict","title":"Resource Conflict","status":409,` +
+				`"detail":"isbn already exist","instance":"/book/2","request_id":"some-request-id"}`,
+		},
+		{
+			path:   "/book/3",
+			status: http.StatusInternalServerError,
+			expected: `{"type":"about:blank","title":"Internal Server Error","status":500,` +
+				`"instance":"/book/3","request_id":"some-request-id"}`,
+		},
+		{
+			path:   "/book/4",
+			status: http.StatusBadRequest,
+			expected: `{"type":"urn:problem-type:validation","title":"Invalid Request","status":400,` +
+				`"detail":"title is a required field","instance":"/book/4","request_id":"some-request-id",` +
+				`"errors":[{"field":"title","rule":"required","message":"title is a required field"}]}`,
+		},
+		{
+			path:           "/book/4",
+			acceptLanguage: "id-ID,id;q=0.9,en;q=0.8",
+			status:         http.StatusBadRequest,
+			expected: `{"type":"urn:problem-type:validation","title":"Invalid Request","status":400,` +
+				`"detail":"title wajib diisi","instance":"/book/4","request_id":"some-request-id",` +
+				`"errors":[{"field":"title","rule":"required","message":"title wajib diisi"}]}`,
+		},
+		{
+			path:   "/author",
+			status: http.StatusNotFound,
+			expected: `{"type":"about:blank","title":"Not Found","status":404,` +
+				`"detail":"Not Found","instance":"/author","request_id":"some-request-id"}`,
+		},
+	}
+	for _, tt := range testcases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		e.ServeHTTP(rec, req)
+		require.Equal(t, tt.status, rec.Code, tt.path)
+		require.Equal(t, base.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType), tt.path)
+		require.JSONEq(t, tt.expected, rec.Body.String(), tt.path)
+	}
+}
+
+func TestHTTPErrorHandler_JSONAPI(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: func() string { return "some-request-id" }}))
+	e.GET("/book/:id", func(c echo.Context) error {
+		if c.Param("id") == "1" {
+			return base.NewNotFoundError("book #%d not found", 1)
+		}
+		return base.NewValidationError(validkit.Struct(struct {
+			Title string `json:"title" validate:"required"`
+		}{}))
+	})
+
+	testcases := []struct {
+		path     string
+		status   int
+		expected string
+	}{
+		{
+			path:   "/book/1",
+			status: http.StatusNotFound,
+			expected: `{"errors":[{"id":"some-request-id","status":"404","title":"Resource Not Found",` +
+				`"detail":"book #1 not found"}]}`,
+		},
+		{
+			path:   "/book/2",
+			status: http.StatusBadRequest,
+			expected: `{"errors":[{"id":"some-request-id","status":"400","code":"required","title":"Invalid Request",` +
+				`"detail":"title is a required field","source":{"pointer":"/data/attributes/title"}}]}`,
+		},
+	}
+	for _, tt := range testcases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(echo.HeaderAccept, base.MIMEApplicationJSONAPI)
+		e.ServeHTTP(rec, req)
+		require.Equal(t, tt.status, rec.Code, tt.path)
+		require.Equal(t, base.MIMEApplicationJSONAPI, rec.Header().Get(echo.HeaderContentType), tt.path)
+		require.JSONEq(t, tt.expected, rec.Body.String(), tt.path)
+	}
+}
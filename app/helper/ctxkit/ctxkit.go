@@ -0,0 +1,49 @@
+// Package ctxkit provide request scoped value carried by context
+package ctxkit
+
+import (
+	"context"
+
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+type key int
+
+const (
+	actorKey key = iota
+	requestIDKey
+	claimsKey
+)
+
+// WithActor return copy of the context which carry the actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor return who perform the request; empty when unknown
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// WithRequestID return copy of the context which carry the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID return ID of the request; empty when unknown
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithClaims return copy of the context which carry claims of the authenticated request
+func WithClaims(ctx context.Context, claims jwtauth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// Claims return claims of the authenticated request; nil when the request is not authenticated
+func Claims(ctx context.Context) jwtauth.Claims {
+	claims, _ := ctx.Value(claimsKey).(jwtauth.Claims)
+	return claims
+}
@@ -0,0 +1,23 @@
+package ctxkit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	require.Empty(t, Actor(ctx))
+	require.Empty(t, RequestID(ctx))
+	require.Nil(t, Claims(ctx))
+
+	ctx = WithRequestID(WithActor(ctx, "some-actor"), "some-request-id")
+	require.Equal(t, "some-actor", Actor(ctx))
+	require.Equal(t, "some-request-id", RequestID(ctx))
+
+	ctx = WithClaims(ctx, jwtauth.Claims{"sub": "some-subject"})
+	require.Equal(t, "some-subject", Claims(ctx).Subject())
+}
@@ -0,0 +1,23 @@
+// Package etagkit provide function to compare entity tag of conditional request
+package etagkit
+
+import "strings"
+
+// Match return whether etag is listed in If-Match or If-None-Match header value
+func Match(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		if weak(strings.TrimSpace(candidate)) == weak(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func weak(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
@@ -0,0 +1,26 @@
+package etagkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	testcases := []struct {
+		header   string
+		etag     string
+		expected bool
+	}{
+		{`"3"`, `"3"`, true},
+		{`"2"`, `"3"`, false},
+		{`*`, `"3"`, true},
+		{`"1", "3"`, `"3"`, true},
+		{`W/"3"`, `"3"`, true},
+		{``, `"3"`, false},
+	}
+
+	for _, tt := range testcases {
+		require.Equal(t, tt.expected, Match(tt.header, tt.etag), tt.header)
+	}
+}
@@ -0,0 +1,51 @@
+// Package imagekit provide image manipulation without external dependency
+package imagekit
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Thumbnail return the image scaled down to the width while keeping its aspect ratio; smaller image is not enlarged
+func Thumbnail(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if width <= 0 || srcW <= width {
+		return src
+	}
+
+	height := srcH * width / srcW
+	if height < 1 {
+		height = 1
+	}
+
+	// NOTE: source is converted once so its pixel is read from typed buffer instead of color interface of each At call
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(bounds)
+		draw.Draw(rgba, bounds, src, bounds.Min, draw.Src)
+	}
+
+	// NOTE: each destination pixel is the average of source pixels it covers (box filter)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := bounds.Min.Y+y*srcH/height, bounds.Min.Y+(y+1)*srcH/height
+		for x := 0; x < width; x++ {
+			x0, x1 := bounds.Min.X+x*srcW/width, bounds.Min.X+(x+1)*srcW/width
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					p := rgba.Pix[i : i+4 : i+4]
+					r, g, b, a, n = r+uint64(p[0]), g+uint64(p[1]), b+uint64(p[2]), a+uint64(p[3]), n+1
+					i += 4
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j], dst.Pix[j+1], dst.Pix[j+2], dst.Pix[j+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
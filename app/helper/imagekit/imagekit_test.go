@@ -0,0 +1,42 @@
+package imagekit
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	t.Run("scaled down", func(t *testing.T) {
+		thumbnail := Thumbnail(src, 100)
+		require.Equal(t, image.Rect(0, 0, 100, 50), thumbnail.Bounds())
+		r, g, b, a := thumbnail.At(50, 25).RGBA()
+		require.Equal(t, []uint32{200, 100, 50, 255}, []uint32{r >> 8, g >> 8, b >> 8, a >> 8})
+	})
+
+	t.Run("other image type", func(t *testing.T) {
+		gray := image.NewGray(image.Rect(10, 10, 210, 110))
+		for y := 10; y < 110; y++ {
+			for x := 10; x < 210; x++ {
+				gray.SetGray(x, y, color.Gray{Y: 120})
+			}
+		}
+		thumbnail := Thumbnail(gray, 50)
+		require.Equal(t, image.Rect(0, 0, 50, 25), thumbnail.Bounds())
+		r, g, b, a := thumbnail.At(0, 0).RGBA()
+		require.Equal(t, []uint32{120, 120, 120, 255}, []uint32{r >> 8, g >> 8, b >> 8, a >> 8})
+	})
+
+	t.Run("not enlarged", func(t *testing.T) {
+		require.Equal(t, src, Thumbnail(src, 800))
+	})
+}
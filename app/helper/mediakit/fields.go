@@ -0,0 +1,77 @@
+package mediakit
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONFields return value of exported struct fields by its JSON name; field which is omitted from JSON is excluded too
+func JSONFields(v interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return fields
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fields
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" && len(tag) == 1 {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embeddedName, embeddedValue := range JSONFields(value.Field(i).Interface()) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = embeddedValue
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if hasOption(tag[1:], "omitempty") && isEmptyValue(value.Field(i)) {
+			continue
+		}
+		fields[name] = value.Field(i).Interface()
+	}
+	return fields
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+// Package mediakit negotiate media type and encode value as XML or CSV which follow its JSON field names
+package mediakit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrNotList returned when value encoded as CSV is not a list
+var ErrNotList = errors.New("mediakit: only list can be encoded as csv")
+
+// Negotiate return the most preferred offer in Accept header; the first offer when the header is empty; empty when nothing is acceptable.
+// Quality of the offer is taken from its most specific media range. The first offer is the default which also stand in for unsupported
+// media types the client prefer when it accept */* (e.g. browser prefer text/html) so other offer is only chosen when it is ranked higher
+func Negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" && len(offers) > 0 {
+		return offers[0]
+	}
+
+	ranges := acceptedRanges(accept)
+	best, bestQuality := "", 0.0
+	for i, offer := range offers {
+		quality := offerQuality(ranges, offer)
+		if i == 0 && quality > 0 {
+			quality = defaultQuality(ranges, offers, quality)
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
+
+type mediaRange struct {
+	name    string
+	quality float64
+}
+
+// acceptedRanges return media ranges in Accept header with its quality value
+func acceptedRanges(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "" {
+			continue
+		}
+		if name == "*" {
+			name = "*/*"
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange{name: name, quality: quality})
+	}
+	return ranges
+}
+
+// offerQuality return quality of the most specific media range which match the offer; zero when nothing match
+func offerQuality(ranges []mediaRange, offer string) float64 {
+	quality, specificity := 0.0, 0
+	for _, r := range ranges {
+		if s := rangeSpecificity(r.name, offer); s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality
+}
+
+// defaultQuality return quality of the acceptable default offer which take the highest quality of unsupported media ranges when */* is accepted
+func defaultQuality(ranges []mediaRange, offers []string, quality float64) float64 {
+	anything := false
+	for _, r := range ranges {
+		anything = anything || (r.name == "*/*" && r.quality > 0)
+	}
+	if !anything {
+		return quality
+	}
+	for _, r := range ranges {
+		if r.quality > quality && !matchAny(r.name, offers) {
+			quality = r.quality
+		}
+	}
+	return quality
+}
+
+func matchAny(name string, offers []string) bool {
+	for _, offer := range offers {
+		if rangeSpecificity(name, offer) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeSpecificity return how specific the media range match the offer; zero when it does not match
+func rangeSpecificity(name, offer string) int {
+	switch {
+	case name == offer:
+		return 3
+	case name == "*/*":
+		return 1
+	case strings.HasSuffix(name, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(name, "*")):
+		return 2
+	}
+	return 0
+}
+
+// WriteCSV write list as CSV with header; column is the JSON field name and nested value is written as JSON
+func WriteCSV(w io.Writer, list interface{}) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	var rows []json.RawMessage
+	if err = json.Unmarshal(data, &rows); err != nil {
+		return ErrNotList
+	}
+
+	var header []string
+	columns := make(map[string]int)
+	records := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		keys, values, err := decodeObject(row)
+		if err != nil {
+			return ErrNotList
+		}
+		records[i] = make(map[string]string)
+		for j, key := range keys {
+			if _, ok := columns[key]; !ok {
+				columns[key] = len(header)
+				header = append(header, key)
+			}
+			records[i][key] = csvValue(values[j])
+		}
+	}
+
+	// NOTE: empty list has no column to write
+	if len(header) == 0 {
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	if err = writer.Write(header); err != nil {
+		return err
+	}
+	for _, record := range records {
+		line := make([]string, len(header))
+		for i, column := range header {
+			line[i] = record[column]
+		}
+		if err = writer.Write(line); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(raw json.RawMessage) string {
+	switch raw[0] {
+	case 'n':
+		return ""
+	case '"':
+		var s string
+		json.Unmarshal(raw, &s)
+		return s
+	}
+	return string(raw)
+}
+
+// decodeObject return keys and values of JSON object in its order
+func decodeObject(raw json.RawMessage) (keys []string, values []json.RawMessage, err error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("mediakit: expect object but got %s", raw)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+	return keys, values, nil
+}
@@ -0,0 +1,110 @@
+package mediakit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type book struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	ParentID  *int64    `json:"parent_id"`
+	Tags      []string  `json:"tags,omitempty"`
+	Secret    string    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "text/csv"}
+	testcases := []struct {
+		accept   string
+		expected string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"text/csv", "text/csv"},
+		{"text/*", "text/csv"},
+		{"text/html, application/xml;q=0.9, */*;q=0.1", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8", "application/json"},
+		{"application/xml, */*;q=0.1", "application/xml"},
+		{"text/html, application/xml;q=0.9", "application/xml"},
+		{"application/json;q=0.5, text/csv", "text/csv"},
+		{"application/xml;q=0, application/json", "application/json"},
+		{"application/xml;q=0, */*", "application/json"},
+		{"text/*;q=0.5, application/json;q=0.2, */*;q=0.1", "text/csv"},
+		{"application/json;q=0, */*", "application/xml"},
+		{"text/html, application/json;q=0, */*", "application/xml"},
+		{"text/html", ""},
+	}
+
+	for _, tt := range testcases {
+		require.Equal(t, tt.expected, Negotiate(tt.accept, offers...), tt.accept)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	updatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	parentID := int64(7)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []*book{
+		{ID: 1, Title: "some, title", UpdatedAt: updatedAt},
+		{ID: 2, Title: "other-title", ParentID: &parentID, Tags: []string{"a", "b"}, UpdatedAt: updatedAt},
+	}))
+	require.Equal(t, "id,title,parent_id,updated_at,tags\n"+
+		"1,\"some, title\",,2020-01-02T03:04:05Z,\n"+
+		"2,other-title,7,2020-01-02T03:04:05Z,\"[\"\"a\"\",\"\"b\"\"]\"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteCSV(&buf, []*book{}))
+	require.Empty(t, buf.String())
+
+	require.Equal(t, ErrNotList, WriteCSV(&buf, book{}))
+}
+
+func TestXML(t *testing.T) {
+	updatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	parentID := int64(7)
+	original := book{ID: 1, Title: "some & title", ParentID: &parentID, Tags: []string{"a", "b"}, Secret: "secret", UpdatedAt: updatedAt}
+
+	b, err := MarshalXML(original, "response")
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><id>1</id><title>some &amp; title</title><parent_id>7</parent_id>`+
+		`<tags><item>a</item><item>b</item></tags><updated_at>2020-01-02T03:04:05Z</updated_at></response>`, string(b))
+
+	var decoded book
+	require.NoError(t, UnmarshalXML(b, &decoded))
+	original.Secret = ""
+	require.Equal(t, original, decoded)
+
+	b, err = MarshalXML(map[string]interface{}{"message": "done", "data": []int{1, 2}}, "response")
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><data><item>1</item><item>2</item></data><message>done</message></response>`, string(b))
+
+	require.Error(t, UnmarshalXML([]byte(`<response><id>one</id></response>`), &decoded))
+}
+
+func TestJSONFields(t *testing.T) {
+	type Book book
+	type result struct {
+		Book
+		Rank float64 `json:"rank"`
+	}
+	updatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.Equal(t, map[string]interface{}{
+		"id":         int64(1),
+		"title":      "some-title",
+		"parent_id":  (*int64)(nil),
+		"updated_at": updatedAt,
+		"rank":       0.5,
+	}, JSONFields(&result{Book: Book{ID: 1, Title: "some-title", Secret: "secret", UpdatedAt: updatedAt}, Rank: 0.5}))
+
+	require.Empty(t, JSONFields(nil))
+	require.Empty(t, JSONFields("some-string"))
+}
@@ -0,0 +1,176 @@
+package mediakit
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// xmlItem is element name of list item
+const xmlItem = "item"
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// MarshalXML encode value as XML document with the root element; element name is JSON field name, list item is named "item" and null is omitted
+func MarshalXML(v interface{}, root string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err = encodeXML(enc, root, data); err != nil {
+		return nil, err
+	}
+	if err = enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeXML(enc *xml.Encoder, name string, raw json.RawMessage) (err error) {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	switch raw[0] {
+	case 'n':
+		return nil
+	case '{':
+		keys, values, err := decodeObject(raw)
+		if err != nil {
+			return err
+		}
+		if err = enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for i, key := range keys {
+			if err = encodeXML(enc, key, values[i]); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case '[':
+		var items []json.RawMessage
+		if err = json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		if err = enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err = encodeXML(enc, xmlItem, item); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	}
+	return enc.EncodeElement(csvValue(raw), start)
+}
+
+// xmlNode is element of XML document
+type xmlNode struct {
+	XMLName  xml.Name
+	Children []*xmlNode `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+// UnmarshalXML decode XML document which is encoded by MarshalXML into value
+func UnmarshalXML(data []byte, v interface{}) error {
+	var root xmlNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	b, err := json.Marshal(root.value(reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// value convert the element into JSON value of the type
+func (n *xmlNode) value(t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	text := strings.TrimSpace(n.Text)
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return text
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		object := make(map[string]interface{})
+		for _, child := range n.Children {
+			if field, ok := fields[child.XMLName.Local]; ok {
+				object[child.XMLName.Local] = child.value(field)
+			}
+		}
+		return object
+	case reflect.Map:
+		object := make(map[string]interface{})
+		for _, child := range n.Children {
+			object[child.XMLName.Local] = child.value(t.Elem())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return text
+		}
+		list := make([]interface{}, len(n.Children))
+		for i, child := range n.Children {
+			list[i] = child.value(t.Elem())
+		}
+		return list
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return json.Number(text)
+	case reflect.Interface:
+		if len(n.Children) > 0 {
+			object := make(map[string]interface{})
+			for _, child := range n.Children {
+				object[child.XMLName.Local] = child.value(t)
+			}
+			return object
+		}
+	}
+	return text
+}
+
+// jsonFields return type of struct fields by its JSON name
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range jsonFields(field.Type) {
+				fields[embeddedName] = embeddedType
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -1,8 +1,18 @@
 package strkit
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
 // ToInt64 convert string to int64
 func ToInt64(s string) (int64, error) {
 	return strconv.ParseInt(s, 10, 64)
 }
+
+// EscapeLike escape wildcard of LIKE pattern so the string is matched literally
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -26,3 +26,8 @@ func TestToInt64(t *testing.T) {
 
 	}
 }
+
+func TestEscapeLike(t *testing.T) {
+	require.Equal(t, "some-name", EscapeLike("some-name"))
+	require.Equal(t, `100\% pure\_go \\ more`, EscapeLike(`100% pure_go \ more`))
+}
@@ -0,0 +1,166 @@
+// Package validkit provide shared validator which report JSON field name and translate its message
+package validkit
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	validator "gopkg.in/go-playground/validator.v9"
+	en_translations "gopkg.in/go-playground/validator.v9/translations/en"
+	id_translations "gopkg.in/go-playground/validator.v9/translations/id"
+)
+
+// FieldError is validation error of a field
+type FieldError struct {
+	// Field is JSON path of the field (e.g. "operations[0].book.title")
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	// NOTE: translation is registered once per translator so every model share the same validator
+	uni      = ut.New(en.New(), en.New(), id.New())
+	validate = newValidator()
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonName)
+
+	trans, _ := uni.GetTranslator("en")
+	en_translations.RegisterDefaultTranslations(v, trans)
+	trans, _ = uni.GetTranslator("id")
+	id_translations.RegisterDefaultTranslations(v, trans)
+	return v
+}
+
+// jsonName return JSON name of the struct field; the struct field name is used when it has no JSON name
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// Struct validate the struct
+func Struct(s interface{}) error {
+	return validate.Struct(s)
+}
+
+// RegisterValidation add custom rule with its message in each language (e.g. "en"); {0} in the message is replaced by field name
+func RegisterValidation(rule string, fn validator.Func, messages map[string]string) (err error) {
+	if err = validate.RegisterValidation(rule, fn); err != nil {
+		return
+	}
+
+	for lang, message := range messages {
+		trans, ok := uni.GetTranslator(lang)
+		if !ok {
+			return errors.New("validkit: unsupported language " + lang)
+		}
+		message := message
+		err = validate.RegisterTranslation(rule, trans,
+			func(trans ut.Translator) error {
+				return trans.Add(rule, message, true)
+			},
+			func(trans ut.Translator, fe validator.FieldError) string {
+				t, _ := trans.T(rule, fe.Field())
+				return t
+			})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Translator return translator of the most preferred language in Accept-Language header; English when none is supported
+func Translator(acceptLanguage string) ut.Translator {
+	for _, lang := range preferredLanguages(acceptLanguage) {
+		if trans, ok := uni.GetTranslator(lang); ok {
+			return trans
+		}
+		// NOTE: fallback to primary language (e.g. "id" of "id-ID")
+		if i := strings.IndexAny(lang, "-_"); i > 0 {
+			if trans, ok := uni.GetTranslator(lang[:i]); ok {
+				return trans
+			}
+		}
+	}
+	return uni.GetFallback()
+}
+
+// preferredLanguages return languages in Accept-Language header ordered by its quality value
+func preferredLanguages(acceptLanguage string) []string {
+	type weighted struct {
+		lang    string
+		quality float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.TrimSpace(params[0]))
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			langs = append(langs, weighted{lang: lang, quality: quality})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].quality > langs[j].quality
+	})
+
+	names := make([]string, len(langs))
+	for i, lang := range langs {
+		names[i] = lang.lang
+	}
+	return names
+}
+
+// FieldErrors return translated error of each field; nil when the error is not validation error
+func FieldErrors(err error, trans ut.Translator) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fieldErrs := make([]FieldError, len(validationErrs))
+	for i, fe := range validationErrs {
+		fieldErrs[i] = FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		}
+	}
+	return fieldErrs
+}
+
+// fieldPath return namespace without the root struct name (e.g. "Book.title" become "title")
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
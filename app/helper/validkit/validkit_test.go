@@ -0,0 +1,68 @@
+package validkit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+type sample struct {
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count,omitempty" validate:"even"`
+	Items []item `json:"items" validate:"dive"`
+}
+
+type item struct {
+	Code string `validate:"max=3"`
+}
+
+func init() {
+	RegisterValidation("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}, map[string]string{
+		"en": "{0} must be even",
+		"id": "{0} harus genap",
+	})
+}
+
+func TestFieldErrors(t *testing.T) {
+	err := Struct(sample{Count: 1, Items: []item{{Code: "ok"}, {Code: "toolong"}}})
+	require.Error(t, err)
+
+	require.Equal(t, []FieldError{
+		{Field: "name", Rule: "required", Message: "name is a required field"},
+		{Field: "count", Rule: "even", Message: "count must be even"},
+		{Field: "items[1].Code", Rule: "max", Param: "3", Message: "Code must be a maximum of 3 characters in length"},
+	}, FieldErrors(err, Translator("en-US")))
+
+	require.Equal(t, []FieldError{
+		{Field: "name", Rule: "required", Message: "name wajib diisi"},
+		{Field: "count", Rule: "even", Message: "count harus genap"},
+		{Field: "items[1].Code", Rule: "max", Param: "3", Message: "panjang maksimal Code adalah 3 karakter"},
+	}, FieldErrors(err, Translator("id-ID")))
+}
+
+func TestFieldErrors_NotValidationError(t *testing.T) {
+	require.Nil(t, FieldErrors(errors.New("some-error"), Translator("")))
+	require.Nil(t, FieldErrors(Struct(sample{Name: "some-name"}), Translator("")))
+}
+
+func TestTranslator(t *testing.T) {
+	testcases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", "en"},
+		{"id", "id"},
+		{"id-ID,id;q=0.9", "id"},
+		{"fr-FR, en;q=0.5, id;q=0.8", "id"},
+		{"id;q=0, en", "en"},
+		{"fr, *", "en"},
+	}
+
+	for _, tt := range testcases {
+		require.Equal(t, tt.expected, Translator(tt.acceptLanguage).Locale(), tt.acceptLanguage)
+	}
+}
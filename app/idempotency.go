@@ -0,0 +1,12 @@
+package app
+
+import (
+	"database/sql"
+
+	"github.com/typical-go/typical-rest-server/pkg/idempotency"
+)
+
+// NewIdempotencyStore return store of idempotent request which is shared across instances
+func NewIdempotencyStore(conn *sql.DB) idempotency.Store {
+	return idempotency.NewPostgres(conn)
+}
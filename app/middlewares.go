@@ -1,14 +1,320 @@
 package app
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
+	"github.com/rs/zerolog"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
+	"github.com/typical-go/typical-rest-server/pkg/idempotency"
+)
+
+const (
+	// headerActor is request header to tell who perform the request when authentication is disabled
+	headerActor = "X-Actor"
+
+	// maxRequestIDLength is maximum length of incoming X-Request-ID which is honoured
+	maxRequestIDLength = 128
+
+	// headerIdempotencyKey is request header to make retried request is not executed twice
+	headerIdempotencyKey = "Idempotency-Key"
+	// headerIdempotentReplayed is response header to tell the response is replayed
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
 )
 
 func initMiddlewares(s *Server) {
-	s.Use(middleware.Logger())
+	routeName := routeNamer(s.Echo)
+	s.Use(requestID)
+	s.Use(requestContext(s.AuthDisabled))
+	s.Use(requestLogger(s.logger))
+	s.Use(requestTimeout(routeName, s.RequestTimeout, s.RouteTimeouts))
 	s.Use(middleware.Recover())
+	if s.AuthDisabled {
+		s.logger.Warn().Msg("authentication is disabled by APP_AUTH_DISABLED; the actor is told by X-Actor header")
+	} else {
+		s.Use(base.Authenticate(s.verifier, publicRoute(routeName, s.PublicRoutes)))
+	}
+	s.Use(idempotent(s.idempotencyStore, s.IdempotencyTTL, s.IdempotencyMaxBody))
 	// check list of middleware at https://echo.labstack.com/middleware
 }
 
 // Put custom middleware belows
 // Example: https://echo.labstack.com/cookbook/middleware
+
+// requestID honour X-Request-ID of the incoming request or generate the new one; the ID is returned in response header
+func requestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		return next(c)
+	}
+}
+
+// validRequestID return whether the incoming request ID is safe to be logged and echoed back
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestContext carry the request ID and the actor in request context so it is recorded by book audit; X-Actor header is only
+// honoured when authentication is disabled otherwise the actor is the subject of verified token
+func requestContext(honourActorHeader bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// NOTE: the actor is the client address until the request is authenticated by bearer token
+			actor := c.RealIP()
+			if header := c.Request().Header.Get(headerActor); honourActorHeader && header != "" {
+				actor = header
+			}
+
+			ctx := c.Request().Context()
+			ctx = ctxkit.WithActor(ctx, actor)
+			ctx = ctxkit.WithRequestID(ctx, c.Response().Header().Get(echo.HeaderXRequestID))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// requestLogger carry the logger with request ID, route and user in request context so log of service, repository
+// and transaction is correlated; the request is logged with its status and latency when it is done
+func requestLogger(logger zerolog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			l := logger.With().
+				Str("request_id", ctxkit.RequestID(req.Context())).
+				Str("method", req.Method).
+				Str("route", c.Path()).
+				Logger().
+				Hook(zerolog.HookFunc(func(e *zerolog.Event, _ zerolog.Level, _ string) {
+					// NOTE: the user is looked up when the log is written since it is known after the request is authenticated
+					e.Str("user", ctxkit.Actor(c.Request().Context()))
+				}))
+			c.SetRequest(req.WithContext(l.WithContext(req.Context())))
+
+			if err := next(c); err != nil {
+				// NOTE: write the error response here so its status is logged
+				c.Error(err)
+			}
+
+			res := c.Response()
+			event := l.Info()
+			if res.Status >= http.StatusInternalServerError {
+				event = l.Error()
+			}
+			event.Str("uri", req.RequestURI).
+				Str("remote_ip", c.RealIP()).
+				Int("status", res.Status).
+				Dur("latency", time.Since(start)).
+				Int64("bytes_out", res.Size).
+				Msg("request")
+			return nil
+		}
+	}
+}
+
+// routeNamer return function to get name of the route which match the request; the route is named after the middleware is
+// registered so the names is collected at first request
+func routeNamer(e *echo.Echo) func(echo.Context) string {
+	var (
+		once  sync.Once
+		names map[string]string
+	)
+	return func(c echo.Context) string {
+		once.Do(func() {
+			names = make(map[string]string)
+			for _, route := range e.Routes() {
+				names[route.Method+" "+route.Path] = route.Name
+			}
+		})
+		return names[c.Request().Method+" "+c.Path()]
+	}
+}
+
+// publicRoute return skipper of authentication for the named route which is public
+func publicRoute(routeName func(echo.Context) string, names []string) middleware.Skipper {
+	public := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name != "" {
+			public[name] = true
+		}
+	}
+	return func(c echo.Context) bool {
+		return public[routeName(c)]
+	}
+}
+
+// requestTimeout cancel the request context when the request is not done within timeout of its route so its database query is
+// cancelled; the named route in routeTimeouts use its own timeout and zero timeout means no timeout
+func requestTimeout(routeName func(echo.Context) string, timeout time.Duration, routeTimeouts map[string]time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			d, ok := routeTimeouts[routeName(c)]
+			if !ok {
+				d = timeout
+			}
+			if d <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			if err != nil && ctx.Err() == context.DeadlineExceeded {
+				return base.NewError(http.StatusServiceUnavailable, fmt.Errorf("request is not done within %s", d))
+			}
+			return err
+		}
+	}
+}
+
+// idempotent replay the response of POST request which is retried with the same Idempotency-Key; the request body is buffered
+// to fingerprint the request so it must not be larger than maxBody
+func idempotent(store idempotency.Store, ttl time.Duration, maxBody int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			key := c.Request().Header.Get(headerIdempotencyKey)
+			if key == "" || c.Request().Method != http.MethodPost {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return base.NewValidationError(
+					fmt.Errorf("%s must not be longer than %d characters", headerIdempotencyKey, maxIdempotencyKeyLength))
+			}
+
+			body, err := ioutil.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxBody))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return base.NewError(http.StatusRequestEntityTooLarge,
+					fmt.Errorf("body of request with %s must not be larger than %d bytes", headerIdempotencyKey, maxBody))
+			}
+			if err != nil {
+				return err
+			}
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			record := idempotency.Record{
+				Key:         key,
+				Subject:     base.Claims(c).Subject(),
+				Route:       c.Request().Method + " " + c.Request().URL.Path,
+				RequestHash: requestHash(c.Request(), body),
+				ExpiresAt:   time.Now().Add(ttl),
+			}
+			stored, err := store.Reserve(c.Request().Context(), record)
+			if err != nil {
+				return err
+			}
+			if stored != nil {
+				return replay(c, record, stored)
+			}
+
+			saved := false
+			defer func() {
+				// NOTE: the key is released when the response is not saved (e.g. panic) so the request can be retried;
+				// it is released even when the request context is already cancelled (e.g. timeout)
+				if !saved {
+					store.Release(context.Background(), record)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err = next(c); err != nil {
+				// NOTE: write the error response here so it is saved
+				c.Error(err)
+			}
+
+			// NOTE: server error is not saved so the request can be retried
+			if c.Response().Status >= http.StatusInternalServerError {
+				return nil
+			}
+
+			record.Status = c.Response().Status
+			record.Header = c.Response().Header()
+			record.Body = recorder.body.Bytes()
+			if err = store.Save(c.Request().Context(), record); err != nil {
+				return err
+			}
+			saved = true
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, record idempotency.Record, stored *idempotency.Record) error {
+	if stored.RequestHash != record.RequestHash {
+		return base.NewError(http.StatusUnprocessableEntity, fmt.Errorf("%s is already used by different request", headerIdempotencyKey))
+	}
+	if !stored.Completed() {
+		return base.NewConflictError(fmt.Errorf("request with the same %s is in progress", headerIdempotencyKey))
+	}
+
+	header := c.Response().Header()
+	for name, values := range stored.Header {
+		// NOTE: request ID belong to the current request
+		if name != echo.HeaderXRequestID {
+			header[name] = values
+		}
+	}
+	header.Set(headerIdempotentReplayed, "true")
+
+	c.Response().WriteHeader(stored.Status)
+	_, err := c.Response().Write(stored.Body)
+	return err
+}
+
+// requestHash return checksum of the request to detect the key is reused by different request
+func requestHash(req *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.RequestURI())
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copy the response body while it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/app/base"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
+	"github.com/typical-go/typical-rest-server/pkg/idempotency"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+// memoryStore keep the idempotency record in memory
+type memoryStore struct {
+	sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func (m *memoryStore) Reserve(ctx context.Context, record idempotency.Record) (*idempotency.Record, error) {
+	m.Lock()
+	defer m.Unlock()
+	if stored, ok := m.records[record.Key+record.Subject+record.Route]; ok {
+		return &stored, nil
+	}
+	m.records[record.Key+record.Subject+record.Route] = record
+	return nil, nil
+}
+
+func (m *memoryStore) Save(ctx context.Context, record idempotency.Record) error {
+	m.Lock()
+	defer m.Unlock()
+	m.records[record.Key+record.Subject+record.Route] = record
+	return nil
+}
+
+func (m *memoryStore) Release(ctx context.Context, record idempotency.Record) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.records, record.Key+record.Subject+record.Route)
+	return nil
+}
+
+func (m *memoryStore) Purge(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotent(t *testing.T) {
+	store := &memoryStore{records: make(map[string]idempotency.Record)}
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(idempotent(store, time.Hour, 64))
+
+	var created int
+	e.POST("/book", func(c echo.Context) error {
+		created++
+		c.Response().Header().Set("Location", "/book/1")
+		return c.JSON(http.StatusCreated, map[string]int{"id": created})
+	})
+	e.POST("/broken", func(c echo.Context) error {
+		return errors.New("some-error")
+	})
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(headerIdempotencyKey, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("without key", func(t *testing.T) {
+		post("/book", "", `{"title":"some-title"}`)
+		post("/book", "", `{"title":"some-title"}`)
+		require.Equal(t, 2, created)
+	})
+
+	t.Run("replay retried request", func(t *testing.T) {
+		first := post("/book", "some-key", `{"title":"some-title"}`)
+		require.Equal(t, http.StatusCreated, first.Code)
+
+		retry := post("/book", "some-key", `{"title":"some-title"}`)
+		require.Equal(t, 3, created)
+		require.Equal(t, http.StatusCreated, retry.Code)
+		require.Equal(t, first.Body.String(), retry.Body.String())
+		require.Equal(t, "/book/1", retry.Header().Get("Location"))
+		require.Equal(t, "true", retry.Header().Get(headerIdempotentReplayed))
+	})
+
+	t.Run("reused key with different payload", func(t *testing.T) {
+		rec := post("/book", "some-key", `{"title":"other-title"}`)
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Equal(t, 3, created)
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		store.Reserve(context.Background(), idempotency.Record{Key: "other-key", Route: "POST /book", RequestHash: requestHash(httptest.NewRequest(http.MethodPost, "/book", nil), nil)})
+		rec := post("/book", "other-key", "")
+		require.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("key of other subject", func(t *testing.T) {
+		store.Reserve(context.Background(), idempotency.Record{Key: "subject-key", Subject: "other-subject", Route: "POST /book"})
+		rec := post("/book", "subject-key", `{"title":"some-title"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, 4, created)
+	})
+
+	t.Run("server error is not saved", func(t *testing.T) {
+		rec := post("/broken", "some-key", "")
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.NotContains(t, store.records, "some-keyPOST /broken")
+	})
+
+	t.Run("too large body", func(t *testing.T) {
+		rec := post("/book", "large-key", `{"title":"`+strings.Repeat("t", 64)+`"}`)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		require.Equal(t, 4, created)
+		require.NotContains(t, store.records, "large-keyPOST /book")
+	})
+
+	t.Run("too long key", func(t *testing.T) {
+		rec := post("/book", strings.Repeat("k", maxIdempotencyKeyLength+1), "")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	e := echo.New()
+	e.Use(requestID)
+	e.GET("/book", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	get := func(id string) string {
+		req := httptest.NewRequest(http.MethodGet, "/book", nil)
+		req.Header.Set(echo.HeaderXRequestID, id)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Header().Get(echo.HeaderXRequestID)
+	}
+
+	require.Equal(t, "some-request-id", get("some-request-id"))
+	require.Len(t, get(""), 32)
+	require.Len(t, get("some\nforged-log"), 32)
+	require.Len(t, get(strings.Repeat("i", maxRequestIDLength+1)), 32)
+	require.NotEqual(t, get(""), get(""))
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(requestID, requestContext(true), requestLogger(zerolog.New(&buf)))
+	e.GET("/book/:id", func(c echo.Context) error {
+		zerolog.Ctx(c.Request().Context()).Info().Msg("find book")
+		return base.NewNotFoundError("book #%s not found", c.Param("id"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/book/1", nil)
+	req.Header.Set(echo.HeaderXRequestID, "some-request-id")
+	req.Header.Set(headerActor, "some-actor")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		require.Equal(t, "some-request-id", entry["request_id"])
+		require.Equal(t, "/book/:id", entry["route"])
+		require.Equal(t, "some-actor", entry["user"])
+	}
+
+	var access map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &access))
+	require.Equal(t, "request", access["message"])
+	require.Equal(t, float64(http.StatusNotFound), access["status"])
+	require.Equal(t, "/book/1", access["uri"])
+	require.Contains(t, access, "latency")
+}
+
+func TestRequestContext(t *testing.T) {
+	actor := func(honourActorHeader bool) string {
+		e := echo.New()
+		e.Use(requestContext(honourActorHeader))
+		var actor string
+		e.GET("/book", func(c echo.Context) error {
+			actor = ctxkit.Actor(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/book", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(headerActor, "some-actor")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+		return actor
+	}
+
+	require.Equal(t, "some-actor", actor(true))
+	require.Equal(t, "10.0.0.1", actor(false))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(requestTimeout(routeNamer(e), 10*time.Millisecond, map[string]time.Duration{"book.export": time.Hour}))
+	e.GET("/book", func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	})
+	e.GET("/book/export", func(c echo.Context) error {
+		d, ok := c.Request().Context().Deadline()
+		require.True(t, ok)
+		require.True(t, time.Until(d) > time.Minute)
+		return c.NoContent(http.StatusOK)
+	}).Name = "book.export"
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/book", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/book/export", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestPublicRoute(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
+	e.Use(base.Authenticate(jwtauth.New(jwtauth.Config{Secret: []byte("some-secret")}), publicRoute(routeNamer(e), []string{"book.list"})))
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/book", ok).Name = base.RouteName("book", base.ActionList)
+	e.POST("/book", ok).Name = base.RouteName("book", base.ActionCreate)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/book", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/book", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -1,5 +1,27 @@
 package app
 
+import "github.com/typical-go/typical-rest-server/app/book/controller"
+
 func initRoutes(s *Server) {
 	s.BaseCRUDController("book", s.bookController)
+	s.GET("/book/search", s.bookController.Search).Name = controller.RouteBookSearch
+	s.PATCH("/book/:id", s.bookController.Patch)
+	s.GET("/book/trash", s.bookController.Trash).Name = controller.RouteBookTrash
+	s.POST("/book/:id/restore", s.bookController.Restore)
+	s.POST("/book/bulk", s.bookController.Bulk).Name = controller.RouteBookBulk
+	s.GET("/book/export", s.bookController.Export).Name = controller.RouteBookExport
+	s.POST("/book/import", s.bookController.Import).Name = controller.RouteBookImport
+	s.GET("/book/isbn/:isbn", s.bookController.GetByISBN)
+	s.PUT("/book/by-key", s.bookController.Upsert)
+	s.POST("/book/:id/tags", s.bookController.AttachTags)
+	s.DELETE("/book/:id/tags/:tag", s.bookController.DetachTag)
+	s.PUT("/book/:id/cover", s.bookController.UploadCover)
+	s.GET("/book/:id/cover", s.bookController.GetCover)
+	s.GET("/book/:id/history", s.bookController.History).Name = controller.RouteBookHistory
+	s.POST("/book/:id/history/:revision/revert", s.bookController.Revert)
+
+	s.BaseCRUDController("author", s.authorController)
+	s.GET("/author/:id/books", s.authorController.Books)
+
+	s.BaseCRUDController("category", s.categoryController)
 }
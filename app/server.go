@@ -9,42 +9,81 @@ import (
 	"time"
 
 	"github.com/labstack/echo"
+	"github.com/rs/zerolog"
+	authorcontroller "github.com/typical-go/typical-rest-server/app/author/controller"
 	"github.com/typical-go/typical-rest-server/app/base"
 	"github.com/typical-go/typical-rest-server/app/book/controller"
+	categorycontroller "github.com/typical-go/typical-rest-server/app/category/controller"
 	"github.com/typical-go/typical-rest-server/config"
+	"github.com/typical-go/typical-rest-server/pkg/idempotency"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
 )
 
 // Server server application
 type Server struct {
 	*echo.Echo
 	config.AppConfig
-	bookController controller.BookController
+	bookController     controller.BookController
+	authorController   authorcontroller.AuthorController
+	categoryController categorycontroller.CategoryController
+	idempotencyStore   idempotency.Store
+	verifier           *jwtauth.Verifier
+	logger             zerolog.Logger
 }
 
 // NewServer return instance of server
 func NewServer(
 	config config.AppConfig,
 	bookController controller.BookController,
+	authorController authorcontroller.AuthorController,
+	categoryController categorycontroller.CategoryController,
+	idempotencyStore idempotency.Store,
+	verifier *jwtauth.Verifier,
 ) *Server {
 
 	s := &Server{
-		Echo:           echo.New(),
-		AppConfig:      config,
-		bookController: bookController,
+		Echo:               echo.New(),
+		AppConfig:          config,
+		bookController:     bookController,
+		authorController:   authorController,
+		categoryController: categoryController,
+		idempotencyStore:   idempotencyStore,
+		verifier:           verifier,
+		logger:             newLogger(config.LogLevel),
 	}
+	s.HTTPErrorHandler = httpErrorHandler
+	s.Binder = &base.Binder{}
 	initMiddlewares(s)
 	initRoutes(s)
 
 	return s
 }
 
-// BaseCRUDController func
+// newLogger return structured logger which write JSON line to stdout; unknown level fallback to info
+func newLogger(level string) zerolog.Logger {
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil || level == "" {
+		logger.Warn().Str("level", level).Msg("unknown log level, fallback to info")
+		lvl = zerolog.InfoLevel
+	}
+	return logger.Level(lvl)
+}
+
+// BaseCRUDController register create read update delete routes of entity; the route is named by base.RouteName to be reversed into link
+// and can be rendered as JSON:API resource of the entity type
 func (s *Server) BaseCRUDController(entity string, crud base.BaseCRUDController) {
-	s.GET(fmt.Sprintf("/%s", entity), crud.List)
-	s.POST(fmt.Sprintf("/%s", entity), crud.Create)
-	s.GET(fmt.Sprintf("/%s/:id", entity), crud.Get)
-	s.PUT(fmt.Sprintf("/%s", entity), crud.Update)
-	s.DELETE(fmt.Sprintf("/%s/:id", entity), crud.Delete)
+	var relationships map[string]string
+	if relater, ok := crud.(base.Relater); ok {
+		relationships = relater.Relationships()
+	}
+	jsonapi := base.JSONAPI(entity, relationships)
+
+	s.GET(fmt.Sprintf("/%s", entity), crud.List, jsonapi).Name = base.RouteName(entity, base.ActionList)
+	s.POST(fmt.Sprintf("/%s", entity), crud.Create, jsonapi).Name = base.RouteName(entity, base.ActionCreate)
+	s.GET(fmt.Sprintf("/%s/:id", entity), crud.Get, jsonapi).Name = base.RouteName(entity, base.ActionGet)
+	s.PUT(fmt.Sprintf("/%s", entity), crud.Update, jsonapi).Name = base.RouteName(entity, base.ActionUpdate)
+	s.DELETE(fmt.Sprintf("/%s/:id", entity), crud.Delete, jsonapi).Name = base.RouteName(entity, base.ActionDelete)
 }
 
 // Serve start serve http request
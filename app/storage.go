@@ -0,0 +1,11 @@
+package app
+
+import (
+	"github.com/typical-go/typical-rest-server/config"
+	"github.com/typical-go/typical-rest-server/pkg/storage"
+)
+
+// NewStorage return storage to keep uploaded file according the configuration
+func NewStorage(config config.AppConfig) storage.Storage {
+	return storage.NewLocal(config.StoragePath)
+}
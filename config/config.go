@@ -1,6 +1,39 @@
 package config
 
+import "time"
+
 // AppConfig contain applicatoin configuration
 type AppConfig struct {
-	Address string `envconfig:"ADDRESS" default:":8089" required:"true"`
+	Address        string        `envconfig:"ADDRESS" default:":8089" required:"true"`
+	TrashRetention time.Duration `envconfig:"TRASH_RETENTION" default:"720h"`
+
+	// StoragePath is root directory of local storage to keep uploaded file
+	StoragePath  string `envconfig:"STORAGE_PATH" default:"storage"`
+	CoverMaxSize int64  `envconfig:"COVER_MAX_SIZE" default:"5242880"`
+
+	// IdempotencyTTL is how long the response of request with Idempotency-Key is replayed
+	IdempotencyTTL time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+	// IdempotencyMaxBody is maximum bytes of the request body which is buffered to fingerprint request with Idempotency-Key
+	// (e.g. multipart import); larger request with the key is rejected
+	IdempotencyMaxBody int64 `envconfig:"IDEMPOTENCY_MAX_BODY" default:"1048576"`
+
+	// LogLevel is minimum level of the structured log (e.g. "debug", "info", "warn")
+	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// RequestTimeout cancel the request and its database query when it is not done in time; zero means no timeout
+	RequestTimeout time.Duration `envconfig:"REQUEST_TIMEOUT" default:"30s"`
+	// RouteTimeouts override RequestTimeout of the named route (e.g. "book.export:10m,book.import:5m")
+	RouteTimeouts map[string]time.Duration `envconfig:"ROUTE_TIMEOUTS" default:"book.export:10m,book.import:5m,book.bulk:2m"`
+
+	// AuthDisabled turn off authentication of the request explicitly (e.g. local development)
+	AuthDisabled bool `envconfig:"AUTH_DISABLED"`
+	// JWTSecret is HMAC key to verify HS256 bearer token and JWTPublicKeyFile is PEM file of RSA key to verify RS256 bearer token;
+	// either is required unless authentication is disabled
+	JWTSecret        string `envconfig:"JWT_SECRET"`
+	JWTPublicKeyFile string `envconfig:"JWT_PUBLIC_KEY_FILE"`
+	// JWTIssuer and JWTAudience is expected "iss" and "aud" of the token; any is accepted when it is empty
+	JWTIssuer   string `envconfig:"JWT_ISSUER"`
+	JWTAudience string `envconfig:"JWT_AUDIENCE"`
+	// PublicRoutes is name of route which is not authenticated (e.g. "book.list,book.get")
+	PublicRoutes []string `envconfig:"PUBLIC_ROUTES" default:"book.list,book.get,author.list,author.get,category.list,category.get"`
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/rs/zerolog"
 )
 
 // ContextKey to get transaction
@@ -18,17 +19,28 @@ type (
 	Context struct {
 		Tx  Tx
 		Err error
+
+		// logger of the context where the transaction is begun
+		logger *zerolog.Logger
 	}
 	// CommitFn is commit function to close the transaction
 	CommitFn func() error
+	// RollbackFn is function to undo the transaction to a savepoint
+	RollbackFn func() error
 	// Handler responsible to handle transaction
 	Handler struct {
-		DB      sq.BaseRunner
+		DB      Runner
 		Context *Context
 	}
+	// Runner is database or transaction to run the query which is cancelled along its context
+	Runner interface {
+		sq.BaseRunner
+		sq.ExecerContext
+		sq.QueryerContext
+	}
 	// Tx is interface for database transaction
 	Tx interface {
-		sq.BaseRunner
+		Runner
 		Rollback() error
 		Commit() error
 	}
@@ -36,7 +48,7 @@ type (
 
 // Begin transaction
 func Begin(parent *context.Context) CommitFn {
-	c := &Context{}
+	c := &Context{logger: zerolog.Ctx(*parent)}
 	*parent = context.WithValue(*parent, ContextKey, c)
 	return c.Commit
 }
@@ -61,6 +73,7 @@ func Use(ctx context.Context, db *sql.DB) (*Handler, error) {
 			return nil, c.Err
 		}
 		c.Tx = tx
+		c.log().Debug().Msg("begin transaction")
 	}
 
 	return &Handler{DB: c.Tx, Context: c}, nil
@@ -84,6 +97,49 @@ func Error(ctx context.Context) error {
 	return nil
 }
 
+// SetError to mark the transaction to be rolled back
+func SetError(ctx context.Context, err error) bool {
+	c := Retrieve(ctx)
+	if c == nil {
+		return false
+	}
+
+	c.Err = err
+	return true
+}
+
+// Savepoint mark current state of transaction. The returned function undo changes after the savepoint and clear the transaction error
+func Savepoint(ctx context.Context, name string) (RollbackFn, error) {
+	c := Retrieve(ctx)
+	if c == nil {
+		return func() error { return nil }, nil
+	}
+
+	// NOTE: transaction is not begun yet so undo by rollback the whole transaction and let next query begin the new one
+	if c.Tx == nil {
+		return func() error {
+			c.Err = nil
+			if c.Tx == nil {
+				return nil
+			}
+			err := c.Tx.Rollback()
+			c.Tx = nil
+			return err
+		}, nil
+	}
+
+	if _, err := c.Tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, fmt.Errorf("dbtxn: %w", err)
+	}
+
+	return func() error {
+		c.log().Debug().Err(c.Err).Str("savepoint", name).Msg("rollback to savepoint")
+		c.Err = nil
+		_, err := c.Tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}, nil
+}
+
 //
 // Context
 //
@@ -94,11 +150,21 @@ func (c *Context) Commit() error {
 		return nil
 	}
 	if c.Err != nil {
+		c.log().Warn().Err(c.Err).Msg("rollback transaction")
 		return c.Tx.Rollback()
 	}
+	c.log().Debug().Msg("commit transaction")
 	return c.Tx.Commit()
 }
 
+// log return logger of the transaction; disabled when the context has no logger
+func (c *Context) log() *zerolog.Logger {
+	if c.logger == nil {
+		return zerolog.Ctx(context.Background())
+	}
+	return c.logger
+}
+
 //
 // Handler
 //
@@ -0,0 +1,45 @@
+// Package idempotency provide storage of response so retried request with the same key is not executed twice
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrKeyConflict returned when the key is still held by other request after several attempts to reserve it
+var ErrKeyConflict = errors.New("idempotency: key is held by other request")
+
+type (
+	// Store keep the response of the first request with the key; key is unique per subject and route
+	Store interface {
+		// Reserve the key for the request. The stored record is returned when the key is already used and not expired yet
+		Reserve(ctx context.Context, record Record) (*Record, error)
+		// Save response of the reserved key
+		Save(ctx context.Context, record Record) error
+		// Release the reserved key of the record so the request can be retried
+		Release(ctx context.Context, record Record) error
+		// Purge expired keys
+		Purge(ctx context.Context, now time.Time) (int64, error)
+	}
+	// Record of the request with its response
+	Record struct {
+		Key string
+		// Subject is who send the request so the same key sent by other subject is not replayed; empty when it is not authenticated
+		Subject string
+		Route   string
+		// RequestHash is checksum of the request to detect the key is reused with different payload
+		RequestHash string
+		// Status is zero while the response is not saved yet
+		Status    int
+		Header    http.Header
+		Body      []byte
+		ExpiresAt time.Time
+	}
+)
+
+// Completed return true when the response is saved
+func (r *Record) Completed() bool {
+	return r.Status > 0
+}
@@ -0,0 +1,137 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	table = "idempotency_keys"
+
+	// maxAttempts to reserve the key which is released by other request in between
+	maxAttempts = 3
+)
+
+// Postgres keep the record in postgres table so it is shared across instances
+type Postgres struct {
+	DB *sql.DB
+}
+
+// NewPostgres return new instance of postgres store
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{DB: db}
+}
+
+// Reserve insert the key or take over the expired one
+func (p *Postgres) Reserve(ctx context.Context, record Record) (*Record, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		reserved, err := p.insert(ctx, record)
+		if err != nil || reserved {
+			return nil, err
+		}
+
+		stored, err := p.find(ctx, record)
+		if err != nil || stored != nil {
+			return stored, err
+		}
+	}
+	return nil, ErrKeyConflict
+}
+
+func (p *Postgres) insert(ctx context.Context, record Record) (reserved bool, err error) {
+	// NOTE: expired key is taken over by the new request
+	query := sq.Insert(table).
+		Columns("key", "subject", "route", "request_hash", "expires_at").
+		Values(record.Key, record.Subject, record.Route, record.RequestHash, record.ExpiresAt).
+		Suffix("ON CONFLICT (key, subject, route) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = NULL, header = NULL, body = NULL, "+
+			"created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at WHERE "+table+".expires_at < ? RETURNING key", time.Now()).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(p.DB)
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	return rows.Next(), rows.Err()
+}
+
+func (p *Postgres) find(ctx context.Context, record Record) (*Record, error) {
+	query := sq.Select("key", "subject", "route", "request_hash", "status", "header", "body", "expires_at").
+		From(table).
+		Where(sq.Eq{"key": record.Key, "subject": record.Subject, "route": record.Route}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(p.DB)
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		stored Record
+		status sql.NullInt64
+		header []byte
+	)
+	if err = rows.Scan(&stored.Key, &stored.Subject, &stored.Route, &stored.RequestHash, &status, &header, &stored.Body, &stored.ExpiresAt); err != nil {
+		return nil, err
+	}
+	stored.Status = int(status.Int64)
+	if len(header) > 0 {
+		if err = json.Unmarshal(header, &stored.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &stored, nil
+}
+
+// Save update the reserved key with the response
+func (p *Postgres) Save(ctx context.Context, record Record) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+
+	_, err = sq.Update(table).
+		Set("status", record.Status).
+		Set("header", header).
+		Set("body", record.Body).
+		Where(sq.Eq{"key": record.Key, "subject": record.Subject, "route": record.Route}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(p.DB).
+		ExecContext(ctx)
+	return err
+}
+
+// Release delete the key which response is not saved yet
+func (p *Postgres) Release(ctx context.Context, record Record) error {
+	_, err := sq.Delete(table).
+		Where(sq.Eq{"key": record.Key, "subject": record.Subject, "route": record.Route, "status": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(p.DB).
+		ExecContext(ctx)
+	return err
+}
+
+// Purge delete the expired keys
+func (p *Postgres) Purge(ctx context.Context, now time.Time) (int64, error) {
+	result, err := sq.Delete(table).
+		Where(sq.Lt{"expires_at": now}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(p.DB).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
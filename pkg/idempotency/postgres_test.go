@@ -0,0 +1,122 @@
+package idempotency_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/pkg/idempotency"
+)
+
+func TestPostgres(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := idempotency.NewPostgres(db)
+	insertSQL := regexp.QuoteMeta(`INSERT INTO idempotency_keys (key,subject,route,request_hash,expires_at) VALUES ($1,$2,$3,$4,$5) ` +
+		`ON CONFLICT (key, subject, route) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = NULL, header = NULL, body = NULL, ` +
+		`created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at WHERE idempotency_keys.expires_at < $6 RETURNING key`)
+	findSQL := regexp.QuoteMeta(`SELECT key, subject, route, request_hash, status, header, body, expires_at FROM idempotency_keys ` +
+		`WHERE key = $1 AND route = $2 AND subject = $3`)
+	expiresAt := time.Now().Add(time.Hour)
+	record := idempotency.Record{Key: "some-key", Subject: "some-subject", Route: "POST /book", RequestHash: "some-hash", ExpiresAt: expiresAt}
+	columns := []string{"key", "subject", "route", "request_hash", "status", "header", "body", "expires_at"}
+
+	t.Run("Reserve", func(t *testing.T) {
+		t.Run("new key", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WithArgs("some-key", "some-subject", "POST /book", "some-hash", expiresAt, sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("some-key"))
+
+			stored, err := store.Reserve(context.Background(), record)
+			require.NoError(t, err)
+			require.Nil(t, stored)
+		})
+
+		t.Run("sql error", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WillReturnError(fmt.Errorf("some-insert-error"))
+
+			_, err := store.Reserve(context.Background(), record)
+			require.EqualError(t, err, "some-insert-error")
+		})
+
+		t.Run("used key", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+			mock.ExpectQuery(findSQL).WithArgs("some-key", "POST /book", "some-subject").
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("some-key", "some-subject", "POST /book", "some-hash", 201, []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":1}`), expiresAt))
+
+			stored, err := store.Reserve(context.Background(), record)
+			require.NoError(t, err)
+			require.Equal(t, &idempotency.Record{
+				Key:         "some-key",
+				Subject:     "some-subject",
+				Route:       "POST /book",
+				RequestHash: "some-hash",
+				Status:      201,
+				Header:      http.Header{"Content-Type": {"application/json"}},
+				Body:        []byte(`{"id":1}`),
+				ExpiresAt:   expiresAt,
+			}, stored)
+			require.True(t, stored.Completed())
+		})
+
+		t.Run("in progress key", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+			mock.ExpectQuery(findSQL).WithArgs("some-key", "POST /book", "some-subject").
+				WillReturnRows(sqlmock.NewRows(columns).AddRow("some-key", "some-subject", "POST /book", "some-hash", nil, nil, nil, expiresAt))
+
+			stored, err := store.Reserve(context.Background(), record)
+			require.NoError(t, err)
+			require.False(t, stored.Completed())
+		})
+
+		t.Run("released in between", func(t *testing.T) {
+			mock.ExpectQuery(insertSQL).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+			mock.ExpectQuery(findSQL).WillReturnRows(sqlmock.NewRows(columns))
+			mock.ExpectQuery(insertSQL).WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("some-key"))
+
+			stored, err := store.Reserve(context.Background(), record)
+			require.NoError(t, err)
+			require.Nil(t, stored)
+		})
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE idempotency_keys SET status = $1, header = $2, body = $3 WHERE key = $4 AND route = $5 AND subject = $6`)).
+			WithArgs(201, []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":1}`), "some-key", "POST /book", "some-subject").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		record := record
+		record.Status = 201
+		record.Header = http.Header{"Content-Type": {"application/json"}}
+		record.Body = []byte(`{"id":1}`)
+		require.NoError(t, store.Save(context.Background(), record))
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM idempotency_keys WHERE key = $1 AND route = $2 AND status IS NULL AND subject = $3`)).
+			WithArgs("some-key", "POST /book", "some-subject").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, store.Release(context.Background(), record))
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM idempotency_keys WHERE expires_at < $1`)).
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		purged, err := store.Purge(context.Background(), now)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), purged)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
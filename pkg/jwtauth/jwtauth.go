@@ -0,0 +1,144 @@
+// Package jwtauth verify JSON Web Token of bearer authentication (RFC 6750)
+package jwtauth
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// bearerScheme is authentication scheme of Authorization header
+const bearerScheme = "Bearer"
+
+var (
+	// ErrMissingToken returned when the request has no bearer token
+	ErrMissingToken = errors.New("jwtauth: missing bearer token")
+	// ErrMissingExpiration returned when the token has no numeric expiration time so it is valid forever
+	ErrMissingExpiration = errors.New("jwtauth: token has no expiration time")
+	// ErrInvalidIssuer returned when the token is not issued by the expected issuer
+	ErrInvalidIssuer = errors.New("jwtauth: invalid token issuer")
+	// ErrInvalidAudience returned when the token is not intended for the expected audience
+	ErrInvalidAudience = errors.New("jwtauth: invalid token audience")
+)
+
+type (
+	// Config of the verifier; the token is verified with HS256 when Secret is given and with RS256 when PublicKey is given
+	Config struct {
+		// Secret is HMAC key of HS256 token
+		Secret []byte
+		// PublicKey is RSA key of RS256 token
+		PublicKey *rsa.PublicKey
+		// Issuer is expected "iss" of the token; any issuer is accepted when it is empty
+		Issuer string
+		// Audience is expected "aud" of the token; any audience is accepted when it is empty
+		Audience string
+	}
+	// Verifier verify signature and registered claims of the token
+	Verifier struct {
+		config Config
+	}
+	// Claims of the verified token
+	Claims map[string]interface{}
+)
+
+// New return new instance of Verifier
+func New(config Config) *Verifier {
+	return &Verifier{config: config}
+}
+
+// LoadPublicKey return RSA public key of the PEM file
+func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: %w", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// BearerToken return the token of Authorization header
+func BearerToken(authorization string) (string, error) {
+	parts := strings.SplitN(authorization, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], bearerScheme) || strings.TrimSpace(parts[1]) == "" {
+		return "", ErrMissingToken
+	}
+	return strings.TrimSpace(parts[1]), nil
+}
+
+// Enabled return whether any key is configured to verify the token
+func (v *Verifier) Enabled() bool {
+	return len(v.config.Secret) > 0 || v.config.PublicKey != nil
+}
+
+// Verify the signature of the token and its "exp", "nbf", "iss" and "aud" claim
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parsed, err := jwt.Parse(token, v.key)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: %w", err)
+	}
+
+	claims, _ := parsed.Claims.(jwt.MapClaims)
+	if !hasExpiration(claims["exp"]) {
+		return nil, ErrMissingExpiration
+	}
+	if v.config.Issuer != "" && !claims.VerifyIssuer(v.config.Issuer, true) {
+		return nil, ErrInvalidIssuer
+	}
+	if v.config.Audience != "" && !hasAudience(claims["aud"], v.config.Audience) {
+		return nil, ErrInvalidAudience
+	}
+	return Claims(claims), nil
+}
+
+// key return the key of signing method of the token; the method is checked so public key is never used as HMAC secret
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if len(v.config.Secret) > 0 {
+			return v.config.Secret, nil
+		}
+	case jwt.SigningMethodRS256.Alg():
+		if v.config.PublicKey != nil {
+			return v.config.PublicKey, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// hasExpiration return whether "exp" claim is numeric date; other type is never expired by the parser so it is rejected
+func hasExpiration(exp interface{}) bool {
+	switch exp.(type) {
+	case float64, json.Number:
+		return true
+	}
+	return false
+}
+
+// hasAudience return whether the audience is in "aud" claim which is single string or list of string
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, item := range v {
+			if s, _ := item.(string); s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subject return "sub" claim which identify who is authenticated
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
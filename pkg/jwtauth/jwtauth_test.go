@@ -0,0 +1,146 @@
+package jwtauth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/pkg/jwtauth"
+)
+
+var secret = []byte("some-secret")
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	verifier := jwtauth.New(jwtauth.Config{Secret: secret, Issuer: "some-issuer", Audience: "some-audience"})
+	require.True(t, verifier.Enabled())
+	exp := time.Now().Add(time.Hour).Unix()
+
+	t.Run("valid token", func(t *testing.T) {
+		claims, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{
+			"sub": "some-subject", "iss": "some-issuer", "aud": "some-audience", "exp": exp,
+		}))
+		require.NoError(t, err)
+		require.Equal(t, "some-subject", claims.Subject())
+	})
+
+	t.Run("audience in list", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{
+			"iss": "some-issuer", "aud": []string{"other-audience", "some-audience"}, "exp": exp,
+		}))
+		require.NoError(t, err)
+	})
+
+	testcases := []struct {
+		name   string
+		token  string
+		expect error
+	}{
+		{
+			name:  "wrong secret",
+			token: sign(t, jwt.SigningMethodHS256, []byte("other-secret"), jwt.MapClaims{"iss": "some-issuer", "aud": "some-audience", "exp": exp}),
+		},
+		{
+			name:  "expired",
+			token: sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "some-issuer", "aud": "some-audience", "exp": time.Now().Add(-time.Minute).Unix()}),
+		},
+		{
+			name: "not valid yet",
+			token: sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{
+				"iss": "some-issuer", "aud": "some-audience", "exp": exp, "nbf": time.Now().Add(time.Minute).Unix(),
+			}),
+		},
+		{
+			name:   "no expiration",
+			token:  sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "some-issuer", "aud": "some-audience"}),
+			expect: jwtauth.ErrMissingExpiration,
+		},
+		{
+			name:   "non-numeric expiration",
+			token:  sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "some-issuer", "aud": "some-audience", "exp": "never"}),
+			expect: jwtauth.ErrMissingExpiration,
+		},
+		{
+			name:   "wrong issuer",
+			token:  sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "other-issuer", "aud": "some-audience", "exp": exp}),
+			expect: jwtauth.ErrInvalidIssuer,
+		},
+		{
+			name:   "wrong audience",
+			token:  sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "some-issuer", "aud": []string{"other-audience"}, "exp": exp}),
+			expect: jwtauth.ErrInvalidAudience,
+		},
+		{
+			name:  "unsigned",
+			token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"iss": "some-issuer", "aud": "some-audience", "exp": exp}),
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.token)
+			require.Error(t, err)
+			if tt.expect != nil {
+				require.Equal(t, tt.expect, err)
+			}
+		})
+	}
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "jwtauth")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	path := filepath.Join(dir, "public.pem")
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	publicKey, err := jwtauth.LoadPublicKey(path)
+	require.NoError(t, err)
+	verifier := jwtauth.New(jwtauth.Config{PublicKey: publicKey})
+
+	claims, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"sub": "some-subject", "exp": time.Now().Add(time.Hour).Unix()}))
+	require.NoError(t, err)
+	require.Equal(t, "some-subject", claims.Subject())
+
+	// NOTE: the public key must not be accepted as HMAC secret
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, der, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}))
+	require.Error(t, err)
+
+	_, err = jwtauth.LoadPublicKey(filepath.Join(dir, "missing.pem"))
+	require.Error(t, err)
+}
+
+func TestBearerToken(t *testing.T) {
+	token, err := jwtauth.BearerToken("Bearer some-token")
+	require.NoError(t, err)
+	require.Equal(t, "some-token", token)
+
+	token, err = jwtauth.BearerToken("bearer  some-token ")
+	require.NoError(t, err)
+	require.Equal(t, "some-token", token)
+
+	for _, authorization := range []string{"", "Bearer", "Bearer ", "Basic c29tZTpzZWNyZXQ="} {
+		_, err = jwtauth.BearerToken(authorization)
+		require.Equal(t, jwtauth.ErrMissingToken, err, authorization)
+	}
+
+	require.False(t, jwtauth.New(jwtauth.Config{}).Enabled())
+}
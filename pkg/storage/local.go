@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local keep the object as file under root directory
+type Local struct {
+	Root string
+}
+
+// NewLocal return new instance of local filesystem storage
+func NewLocal(root string) *Local {
+	return &Local{Root: root}
+}
+
+// Put write the data to the file atomically
+func (l *Local) Put(key string, data []byte) (err error) {
+	name, err := l.filename(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	// NOTE: write to temporary file then rename so reader never get partially written object
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Get read the file
+func (l *Local) Get(key string) (*Object, error) {
+	name, err := l.filename(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{
+		Data:    data,
+		ModTime: info.ModTime(),
+		ETag:    fmt.Sprintf("%x", sha1.Sum(data)),
+	}, nil
+}
+
+// Delete remove the file; deleting missing object is not an error
+func (l *Local) Delete(key string) error {
+	name, err := l.filename(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// filename return file path of the key; key is not allowed to escape the root directory
+func (l *Local) filename(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || cleaned != "/"+strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("storage: invalid key '%s'", key)
+	}
+	return filepath.Join(l.Root, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,47 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/typical-go/typical-rest-server/pkg/storage"
+)
+
+func TestLocal(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	local := storage.NewLocal(root)
+
+	t.Run("get missing object", func(t *testing.T) {
+		_, err := local.Get("book/1/cover/original")
+		require.Equal(t, storage.ErrNotFound, err)
+	})
+
+	t.Run("put and get", func(t *testing.T) {
+		require.NoError(t, local.Put("book/1/cover/original", []byte("some-data")))
+
+		object, err := local.Get("book/1/cover/original")
+		require.NoError(t, err)
+		require.Equal(t, []byte("some-data"), object.Data)
+		require.Equal(t, "2e75c69823968a244f0c5559bb468f365f1211b6", object.ETag)
+		require.False(t, object.ModTime.IsZero())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, local.Delete("book/1/cover/original"))
+		require.NoError(t, local.Delete("book/1/cover/original"))
+
+		_, err := local.Get("book/1/cover/original")
+		require.Equal(t, storage.ErrNotFound, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		for _, key := range []string{"", "/", "../secret", "book/../../secret", "book//1"} {
+			require.Error(t, local.Put(key, []byte("some-data")), key)
+		}
+	})
+}
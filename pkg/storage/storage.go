@@ -0,0 +1,26 @@
+// Package storage provide abstraction to keep binary object outside the database
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound returned when no object is stored with the key
+var ErrNotFound = errors.New("storage: object not found")
+
+type (
+	// Storage keep binary object by its key; key is slash separated path (e.g. "book/1/cover/original")
+	Storage interface {
+		Put(key string, data []byte) error
+		Get(key string) (*Object, error)
+		Delete(key string) error
+	}
+	// Object is binary object kept in the storage
+	Object struct {
+		Data    []byte
+		ModTime time.Time
+		// ETag is checksum of the data to validate cache
+		ETag string
+	}
+)
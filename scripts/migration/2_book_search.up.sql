@@ -0,0 +1,6 @@
+ALTER TABLE books ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+ setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+ setweight(to_tsvector('english', coalesce(author, '')), 'B')
+) STORED;
+
+CREATE INDEX books_search_vector_idx ON books USING GIN (search_vector);
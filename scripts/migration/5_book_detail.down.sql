@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS books_isbn_key;
+ALTER TABLE books
+ DROP COLUMN IF EXISTS isbn,
+ DROP COLUMN IF EXISTS published_year,
+ DROP COLUMN IF EXISTS publisher,
+ DROP COLUMN IF EXISTS language,
+ DROP COLUMN IF EXISTS page_count;
@@ -0,0 +1,8 @@
+ALTER TABLE books
+ ADD COLUMN isbn VARCHAR (13) NOT NULL DEFAULT '',
+ ADD COLUMN published_year INTEGER NOT NULL DEFAULT 0,
+ ADD COLUMN publisher VARCHAR (255) NOT NULL DEFAULT '',
+ ADD COLUMN language VARCHAR (8) NOT NULL DEFAULT '',
+ ADD COLUMN page_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE isbn <> '' AND deleted_at IS NULL;
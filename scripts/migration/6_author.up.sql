@@ -0,0 +1,23 @@
+CREATE TABLE authors (
+ id serial PRIMARY KEY,
+ name VARCHAR (255) NOT NULL,
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX authors_name_key ON authors (name);
+
+CREATE TABLE book_authors (
+ book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+ author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+ position INTEGER NOT NULL DEFAULT 0,
+ PRIMARY KEY (book_id, author_id)
+);
+
+CREATE INDEX book_authors_author_id_idx ON book_authors (author_id);
+
+INSERT INTO authors (name)
+ SELECT DISTINCT trim(author) FROM books WHERE trim(author) <> '';
+
+INSERT INTO book_authors (book_id, author_id)
+ SELECT b.id, a.id FROM books b JOIN authors a ON a.name = trim(b.author);
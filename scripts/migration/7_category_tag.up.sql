@@ -0,0 +1,26 @@
+CREATE TABLE categories (
+ id serial PRIMARY KEY,
+ name VARCHAR (255) NOT NULL,
+ parent_id INTEGER REFERENCES categories (id),
+ updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX categories_name_key ON categories (COALESCE(parent_id, 0), name);
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+
+CREATE TABLE book_categories (
+ book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+ category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+ PRIMARY KEY (book_id, category_id)
+);
+
+CREATE INDEX book_categories_category_id_idx ON book_categories (category_id);
+
+CREATE TABLE book_tags (
+ book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+ tag VARCHAR (64) NOT NULL,
+ PRIMARY KEY (book_id, tag)
+);
+
+CREATE INDEX book_tags_tag_idx ON book_tags (tag);
@@ -0,0 +1,13 @@
+-- NOTE: book_id has no foreign key so the history is kept after the book is purged
+CREATE TABLE book_audit (
+ id BIGSERIAL PRIMARY KEY,
+ book_id INTEGER NOT NULL,
+ action VARCHAR (16) NOT NULL,
+ old_value JSONB,
+ new_value JSONB,
+ actor VARCHAR (255) NOT NULL DEFAULT '',
+ request_id VARCHAR (64) NOT NULL DEFAULT '',
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX book_audit_book_id_idx ON book_audit (book_id, id);
@@ -0,0 +1,15 @@
+-- NOTE: status is null while the first request is still in progress; subject is empty when the request is not authenticated
+CREATE TABLE idempotency_keys (
+ key VARCHAR (255) NOT NULL,
+ subject TEXT NOT NULL DEFAULT '',
+ route VARCHAR (255) NOT NULL,
+ request_hash CHAR (64) NOT NULL,
+ status INTEGER,
+ header JSONB,
+ body BYTEA,
+ created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ expires_at TIMESTAMP NOT NULL,
+ PRIMARY KEY (key, subject, route)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
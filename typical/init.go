@@ -1,12 +1,23 @@
 package typical
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/typical-go/typical-rest-server/app"
+	authorcontroller "github.com/typical-go/typical-rest-server/app/author/controller"
+	authorrepository "github.com/typical-go/typical-rest-server/app/author/repository"
 	"github.com/typical-go/typical-rest-server/app/book/controller"
 	"github.com/typical-go/typical-rest-server/app/book/repository"
 	"github.com/typical-go/typical-rest-server/app/book/service"
+	categorycontroller "github.com/typical-go/typical-rest-server/app/category/controller"
+	categoryrepository "github.com/typical-go/typical-rest-server/app/category/repository"
+	categoryservice "github.com/typical-go/typical-rest-server/app/category/service"
+	"github.com/typical-go/typical-rest-server/app/helper/ctxkit"
 	"github.com/typical-go/typical-rest-server/config"
+	"github.com/typical-go/typical-rest-server/pkg/idempotency"
 	"github.com/typical-go/typical-rest-server/typical/appctx"
 	"github.com/typical-go/typical-rest-server/typical/module"
 )
@@ -29,16 +40,47 @@ func init() {
 			},
 			Constructors: []interface{}{
 				app.NewServer,
+				app.NewStorage,
+				app.NewIdempotencyStore,
+				app.NewVerifier,
 				controller.NewBookController,
 				service.NewBookService,
+				service.NewCoverService,
 				repository.NewBookRepository,
+				authorcontroller.NewAuthorController,
+				authorrepository.NewAuthorRepository,
+				categorycontroller.NewCategoryController,
+				categoryservice.NewCategoryService,
+				categoryrepository.NewCategoryRepository,
 			},
 			Action: func(s *app.Server) error {
 				return s.Serve()
 			},
+			Commands: []appctx.Command{
+				{
+					Name: "purge",
+					Action: func(cfg config.AppConfig, bookService service.BookService, idempotencyStore idempotency.Store) error {
+						purged, err := bookService.PurgeBook(ctxkit.WithActor(context.Background(), "cli:purge"), cfg.TrashRetention)
+						if err != nil {
+							return err
+						}
+						log.Printf("Purge %d book(s) deleted more than %s ago", purged, cfg.TrashRetention)
+
+						expired, err := idempotencyStore.Purge(context.Background(), time.Now())
+						if err != nil {
+							return err
+						}
+						log.Printf("Purge %d expired idempotency key(s)", expired)
+						return nil
+					},
+				},
+			},
 			TestTargets: []string{
 				"./app/book/controller",
 				"./app/book/repository",
+				"./app/author/repository",
+				"./app/category/repository",
+				"./app/crud",
 			},
 			MockTargets: []string{
 				"./app/book/repository/book_repo.go",